@@ -0,0 +1,506 @@
+package dynmgrm
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/miyamo2/godynamo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// batchExecuteStatementLimit is the maximum number of statements in a BatchExecuteStatement request.
+const batchExecuteStatementLimit = 25
+
+// BatchCreateFailure is the failure of an item that could not be written.
+type BatchCreateFailure struct {
+	// Index is the index of the item in the slice passed to Create.
+	Index int
+	// Code is the error code returned by DynamoDB.
+	Code string
+	// Message is the error message returned by DynamoDB.
+	Message string
+}
+
+// BatchCreateError occurs when some items of the slice passed to Create could not be written.
+type BatchCreateError struct {
+	// Failures are the items that could not be written, in the order of the slice passed to Create.
+	Failures []BatchCreateFailure
+}
+
+func (e *BatchCreateError) Error() string {
+	msgs := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		msgs = append(msgs, fmt.Sprintf("[%d] %s: %s", f.Index, f.Code, f.Message))
+	}
+	return fmt.Sprintf("failed to create %d item(s): %s", len(e.Failures), strings.Join(msgs, ", "))
+}
+
+// partiqlStatement is a PartiQL statement with its bind variables.
+type partiqlStatement struct {
+	sql  string
+	vars []interface{}
+}
+
+// parameters marshals the bind variables to the parameters of DynamoDB.
+func (s partiqlStatement) parameters() ([]types.AttributeValue, error) {
+	params := make([]types.AttributeValue, 0, len(s.vars))
+	for i, v := range s.vars {
+		av, err := godynamo.ToAttributeValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling parameter %d-th for statement <%s>: %w", i+1, s.sql, err)
+		}
+		params = append(params, av)
+	}
+	return params, nil
+}
+
+// create returns the create callback that writes every item when a slice is passed.
+//
+// In a transaction, each item is added to it as an INSERT statement.
+// Otherwise, items are written by BatchExecuteStatement in chunks of 25.
+// Statements with ON CONFLICT are written by upsert, and the ones with Condition or Overwrite by putItems.
+func create(config *callbacks.Config) func(db *gorm.DB) {
+	createOne := callbacks.Create(config)
+	createItems := func(db *gorm.DB) {
+		rv := db.Statement.ReflectValue
+		if db.Statement.SQL.Len() != 0 || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() <= 1 {
+			createOne(db)
+			return
+		}
+
+		if db.Statement.Schema != nil && !db.Statement.Unscoped {
+			for _, c := range db.Statement.Schema.CreateClauses {
+				db.Statement.AddClause(c)
+			}
+		}
+		db.Statement.AddClauseIfNotExists(clause.Insert{})
+		values := callbacks.ConvertToCreateValues(db.Statement)
+
+		stmts := make([]partiqlStatement, 0, len(values.Values))
+		for _, row := range values.Values {
+			db.Statement.SQL.Reset()
+			db.Statement.Vars = nil
+			db.Statement.AddClause(clause.Values{Columns: values.Columns, Values: [][]interface{}{row}})
+			db.Statement.Build(db.Statement.BuildClauses...)
+			stmts = append(stmts, partiqlStatement{
+				sql:  db.Statement.SQL.String(),
+				vars: db.Statement.Vars,
+			})
+		}
+		// for logging and dry run
+		db.Statement.SQL.Reset()
+		db.Statement.Vars = nil
+		for i, stmt := range stmts {
+			if i > 0 {
+				db.Statement.WriteString("; ")
+			}
+			db.Statement.WriteString(stmt.sql)
+			db.Statement.Vars = append(db.Statement.Vars, stmt.vars...)
+		}
+
+		if db.DryRun || db.Error != nil {
+			return
+		}
+		if inTransaction(db) {
+			for _, stmt := range stmts {
+				result, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, stmt.sql, stmt.vars...)
+				if err != nil {
+					db.AddError(err)
+					return
+				}
+				rowsAffected, _ := result.RowsAffected()
+				db.RowsAffected += rowsAffected
+			}
+			return
+		}
+		batchCreate(db, stmts)
+	}
+	return func(db *gorm.DB) {
+		if db.Error != nil {
+			return
+		}
+		if onConflict, ok := onConflictOf(db.Statement); ok {
+			upsert(db, onConflict, createItems)
+			return
+		}
+		if _, ok := conditionsOf(db.Statement); ok || overwrites(db.Statement) {
+			createConditional(db)
+			return
+		}
+		createItems(db)
+	}
+}
+
+// batchCreate writes items by BatchExecuteStatement in chunks of 25.
+//
+// Without the DynamoDB client, the items are written one by one through the connection instead.
+func batchCreate(db *gorm.DB, stmts []partiqlStatement) {
+	dialector, ok := db.Dialector.(*Dialector)
+	if !ok || dialector.client == nil {
+		createEach(db, stmts)
+		return
+	}
+
+	var failures []BatchCreateFailure
+	for offset := 0; offset < len(stmts); offset += batchExecuteStatementLimit {
+		chunk := stmts[offset:min(offset+batchExecuteStatementLimit, len(stmts))]
+		requests := make([]types.BatchStatementRequest, 0, len(chunk))
+		for _, stmt := range chunk {
+			params, err := stmt.parameters()
+			if err != nil {
+				db.AddError(err)
+				return
+			}
+			request := types.BatchStatementRequest{Statement: aws.String(stmt.sql)}
+			if len(params) > 0 {
+				request.Parameters = params
+			}
+			requests = append(requests, request)
+		}
+
+		output, err := dialector.client.BatchExecuteStatement(
+			db.Statement.Context,
+			&dynamodb.BatchExecuteStatementInput{Statements: requests})
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		for i := range chunk {
+			if i < len(output.Responses) && output.Responses[i].Error != nil {
+				serr := output.Responses[i].Error
+				failures = append(failures, BatchCreateFailure{
+					Index:   offset + i,
+					Code:    string(serr.Code),
+					Message: aws.ToString(serr.Message),
+				})
+				continue
+			}
+			db.RowsAffected++
+		}
+	}
+	if len(failures) > 0 {
+		db.AddError(&BatchCreateError{Failures: failures})
+	}
+}
+
+// createEach writes items one by one through the connection,
+// and reports the failures in the same way as batchCreate.
+func createEach(db *gorm.DB, stmts []partiqlStatement) {
+	var failures []BatchCreateFailure
+	for i, stmt := range stmts {
+		result, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, stmt.sql, stmt.vars...)
+		if err != nil {
+			failure := BatchCreateFailure{Index: i, Message: err.Error()}
+			var apiErr smithy.APIError
+			if errors.As(err, &apiErr) {
+				failure.Code = apiErr.ErrorCode()
+				failure.Message = apiErr.ErrorMessage()
+			}
+			failures = append(failures, failure)
+			continue
+		}
+		rowsAffected, _ := result.RowsAffected()
+		db.RowsAffected += rowsAffected
+	}
+	if len(failures) > 0 {
+		db.AddError(&BatchCreateError{Failures: failures})
+	}
+}
+
+// inTransaction reports whether the statement is in a transaction begun by the user.
+// Transactions that gorm begins by default are not included.
+func inTransaction(db *gorm.DB) bool {
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); !ok {
+		return false
+	}
+	_, ok := db.InstanceGet("gorm:started_transaction")
+	return !ok
+}
+
+// query is the query callback that scans the rows with timeRows.
+// Queries with Page read only the page by ExecuteStatement.
+// Queries in a transaction are added to the transaction, and scanned on commit.
+func query(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	if !resolveImplicitOrder(db.Statement) {
+		return
+	}
+	page, paged := pageOf(db.Statement)
+	tx, inTx := db.Statement.ConnPool.(*transaction)
+	if paged && inTx {
+		db.AddError(fmt.Errorf("%w: Page in transactions", ErrDynmgrmAreNotSupported))
+		return
+	}
+	if paged || inTx {
+		// LIMIT and WITH are not accepted by ExecuteTransaction, and are passed as the parameters of ExecuteStatement
+		db.Statement.BuildClauses = pageClauses(db.Statement.BuildClauses)
+	}
+	callbacks.BuildQuerySQL(db)
+	if db.DryRun || db.Error != nil {
+		return
+	}
+	if paged {
+		queryPage(db, page)
+		return
+	}
+	if inTx {
+		db.AddError(tx.addRead(db))
+		return
+	}
+	rows, err := db.Statement.ConnPool.QueryContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	defer func() {
+		db.AddError(rows.Close())
+	}()
+	gorm.Scan(&timeRows{Rows: rows}, db, 0)
+}
+
+// update returns the update callback that scans the item returned by RETURNING into the model.
+// Statements with Condition are executed by execConditional.
+// Statements without both of them are executed by the default callback of gorm.
+func update(config *callbacks.Config) func(db *gorm.DB) {
+	updateWithoutReturning := callbacks.Update(config)
+	return func(db *gorm.DB) {
+		_, returning := db.Statement.Clauses["RETURNING"]
+		_, conditional := conditionsOf(db.Statement)
+		if (!returning && !conditional) || db.Error != nil {
+			updateWithoutReturning(db)
+			return
+		}
+		if db.Statement.Schema != nil {
+			for _, c := range db.Statement.Schema.UpdateClauses {
+				db.Statement.AddClause(c)
+			}
+		}
+		if db.Statement.SQL.Len() == 0 {
+			db.Statement.AddClauseIfNotExists(clause.Update{})
+			if _, ok := db.Statement.Clauses["SET"]; !ok {
+				set := callbacks.ConvertToAssignments(db.Statement)
+				if len(set) == 0 {
+					return
+				}
+				defer delete(db.Statement.Clauses, "SET")
+				db.Statement.AddClause(set)
+			}
+			db.Statement.Build(db.Statement.BuildClauses...)
+		}
+		checkMissingWhereConditions(db)
+
+		dest := db.Statement.Dest
+		if db.Statement.ReflectValue.CanAddr() {
+			db.Statement.Dest = db.Statement.ReflectValue.Addr().Interface()
+		}
+		if conditional {
+			execConditional(db, returning)
+		} else {
+			queryReturning(db)
+		}
+		db.Statement.Dest = dest
+	}
+}
+
+// committedUpdatesKey is the key of the number of the updates added to the default transaction of gorm.
+const committedUpdatesKey = "dynmgrm:committed_updates"
+
+// countUpdates returns the update callback that records the number of the statements
+// that the callback adds to the default transaction of gorm.
+func countUpdates(update func(db *gorm.DB)) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if _, ok := db.InstanceGet("gorm:started_transaction"); !ok {
+			update(db)
+			return
+		}
+		buffer, ok := db.Statement.ConnPool.(statementBuffer)
+		if !ok {
+			update(db)
+			return
+		}
+		n := buffer.buffered()
+		update(db)
+		db.InstanceSet(committedUpdatesKey, buffer.buffered()-n)
+	}
+}
+
+// reportCommittedUpdates sets RowsAffected to the number of the updates committed in the default transaction of gorm,
+// which is unknown until the commit.
+// Each of them has affected its item, since DynamoDB cancels the transaction if an UPDATE does not find its item.
+func reportCommittedUpdates(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	if n, ok := db.InstanceGet(committedUpdatesKey); ok && n.(int) > 0 {
+		db.RowsAffected = int64(n.(int))
+	}
+}
+
+// deleteItems returns the delete callback that scans the item returned by RETURNING into the model.
+// Statements with Condition are executed by execConditional.
+// Statements without both of them are executed by the default callback of gorm.
+func deleteItems(config *callbacks.Config) func(db *gorm.DB) {
+	deleteWithoutReturning := callbacks.Delete(config)
+	return func(db *gorm.DB) {
+		_, returning := db.Statement.Clauses["RETURNING"]
+		_, conditional := conditionsOf(db.Statement)
+		if (!returning && !conditional) || db.Error != nil {
+			deleteWithoutReturning(db)
+			return
+		}
+		if db.Statement.Schema != nil {
+			for _, c := range db.Statement.Schema.DeleteClauses {
+				db.Statement.AddClause(c)
+			}
+		}
+		if db.Statement.SQL.Len() == 0 {
+			db.Statement.AddClauseIfNotExists(clause.Delete{})
+			if db.Statement.Schema != nil {
+				_, queryValues := schema.GetIdentityFieldValuesMap(db.Statement.Context, db.Statement.ReflectValue, db.Statement.Schema.PrimaryFields)
+				column, values := schema.ToQueryValues(db.Statement.Table, db.Statement.Schema.PrimaryFieldDBNames, queryValues)
+				if len(values) > 0 {
+					db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+				}
+			}
+			db.Statement.AddClauseIfNotExists(clause.From{})
+			db.Statement.Build(db.Statement.BuildClauses...)
+		}
+		checkMissingWhereConditions(db)
+		if conditional {
+			execConditional(db, returning)
+			return
+		}
+		queryReturning(db)
+	}
+}
+
+// checkMissingWhereConditions adds gorm.ErrMissingWhereClause unless the statement has conditions or is global.
+func checkMissingWhereConditions(db *gorm.DB) {
+	if db.AllowGlobalUpdate || db.Error != nil {
+		return
+	}
+	where, ok := db.Statement.Clauses["WHERE"]
+	if ok {
+		if _, softDelete := db.Statement.Clauses["soft_delete_enabled"]; softDelete {
+			whereClause, _ := where.Expression.(clause.Where)
+			ok = len(whereClause.Exprs) > 1
+		}
+	}
+	if !ok {
+		db.AddError(gorm.ErrMissingWhereClause)
+	}
+}
+
+// queryReturning runs the statement with RETURNING and scans the returned item into the destination.
+//
+// The default transaction that gorm begins holds only the statement,
+// so the statement runs outside it, since DynamoDB does not return items from transactions.
+func queryReturning(db *gorm.DB) {
+	if db.DryRun || db.Error != nil {
+		return
+	}
+	connPool := db.Statement.ConnPool
+	if _, ok := connPool.(gorm.TxCommitter); ok {
+		if inTransaction(db) {
+			db.AddError(ErrReturningInTransaction)
+			return
+		}
+		connPool = db.Config.ConnPool
+	}
+	rows, err := connPool.QueryContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	defer func() {
+		db.AddError(rows.Close())
+	}()
+	gorm.Scan(&timeRows{Rows: rows}, db, 0)
+}
+
+// compatibility check
+var _ gorm.Rows = (*timeRows)(nil)
+
+var timeTypes = []reflect.Type{
+	reflect.TypeOf(time.Time{}),
+	reflect.TypeOf(sql.NullTime{}),
+	reflect.TypeOf(gorm.DeletedAt{}),
+}
+
+// timeRows is a gorm.Rows that scans the string attributes into time.Time, sql.NullTime and gorm.DeletedAt,
+// since database/sql does not convert them.
+type timeRows struct {
+	gorm.Rows
+}
+
+// Scan implements gorm.Rows.
+func (r *timeRows) Scan(dest ...interface{}) error {
+	values := make([]interface{}, len(dest))
+	args := make([]interface{}, len(dest))
+	isTime := make([]bool, len(dest))
+	for i, d := range dest {
+		args[i] = d
+		t := reflect.TypeOf(d)
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if slices.Contains(timeTypes, t) {
+			args[i] = &values[i]
+			isTime[i] = true
+		}
+	}
+	if err := r.Rows.Scan(args...); err != nil {
+		return err
+	}
+	for i, d := range dest {
+		if !isTime[i] {
+			continue
+		}
+		v := values[i]
+		if s, ok := v.(string); ok {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return fmt.Errorf("error parsing %d-th column as time: %w", i+1, err)
+			}
+			v = t
+		}
+		if err := assignTime(d, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignTime assigns the time.Time or nil to the pointer to the time type, or to the pointer of the pointer.
+func assignTime(dest interface{}, v interface{}) error {
+	rv := reflect.ValueOf(dest).Elem()
+	if rv.Kind() == reflect.Pointer {
+		if v == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		rv.Set(reflect.New(rv.Type().Elem()))
+		rv = rv.Elem()
+	}
+	switch target := rv.Addr().Interface().(type) {
+	case *time.Time:
+		t, _ := v.(time.Time)
+		*target = t
+	case sql.Scanner:
+		return target.Scan(v)
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package dynmgrm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/miyamo2/dynmgrm/internal/mocks"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"testing"
+)
+
+var (
+	_ gorm.ConnPool         = (*fakeConnPool)(nil)
+	_ gorm.ConnPoolBeginner = (*fakeConnPool)(nil)
+	_ gorm.TxCommitter      = (*fakeTx)(nil)
+	_ driver.Result         = (*fakeResult)(nil)
+)
+
+var errFakeConnPoolQueryFail = errors.New("query is not supported by fakeConnPool")
+
+// fakeConnPool is a gorm.ConnPool that records executed statements.
+type fakeConnPool struct {
+	execs        []partiqlStatement
+	rowsAffected int64
+	err          error
+	commitErr    error
+}
+
+func (f *fakeConnPool) PrepareContext(_ context.Context, _ string) (*sql.Stmt, error) {
+	return nil, errFakeConnPoolQueryFail
+}
+
+func (f *fakeConnPool) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	f.execs = append(f.execs, partiqlStatement{sql: query, vars: args})
+	return &fakeResult{rowsAffected: f.rowsAffected}, f.err
+}
+
+func (f *fakeConnPool) QueryContext(_ context.Context, _ string, _ ...interface{}) (*sql.Rows, error) {
+	return nil, errFakeConnPoolQueryFail
+}
+
+func (f *fakeConnPool) QueryRowContext(_ context.Context, _ string, _ ...interface{}) *sql.Row {
+	return nil
+}
+
+func (f *fakeConnPool) BeginTx(_ context.Context, _ *sql.TxOptions) (gorm.ConnPool, error) {
+	return &fakeTx{fakeConnPool: f}, nil
+}
+
+// fakeTx is a transaction of fakeConnPool.
+type fakeTx struct {
+	*fakeConnPool
+}
+
+func (f *fakeTx) Commit() error {
+	return f.commitErr
+}
+
+func (f *fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeResult struct {
+	rowsAffected int64
+}
+
+func (f *fakeResult) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeResult) RowsAffected() (int64, error) {
+	return f.rowsAffected, nil
+}
+
+type testItem struct {
+	PK   string `gorm:"primaryKey"`
+	SK   int    `gorm:"primaryKey"`
+	Name string
+}
+
+// openTestDB returns *gorm.DB with Dialector that uses pool and client.
+// If config is nil, the default transaction is skipped.
+func openTestDB(t *testing.T, pool gorm.ConnPool, client DynamoDBAPI, config *gorm.Config) *gorm.DB {
+	t.Helper()
+	if config == nil {
+		config = &gorm.Config{SkipDefaultTransaction: true}
+	}
+	db, err := gorm.Open(
+		&Dialector{
+			conn:                pool,
+			callbacksRegisterer: &callbacksRegisterer{},
+			client:              client,
+		},
+		config)
+	if err != nil {
+		t.Fatalf("failed to open gorm.DB: %v", err)
+	}
+	return db
+}
+
+func newTestItems(n int) []testItem {
+	items := make([]testItem, 0, n)
+	for i := 0; i < n; i++ {
+		items = append(items, testItem{PK: "Partition1", SK: i, Name: fmt.Sprintf("Item%d", i)})
+	}
+	return items
+}
+
+func Test_create(t *testing.T) {
+	const insertSQL = `INSERT INTO "test_items" VALUE {'pk' : ?, 'sk' : ?, 'name' : ?}`
+	type want struct {
+		execs        []partiqlStatement
+		batchSizes   []int
+		rowsAffected int64
+		err          error
+		failures     []BatchCreateFailure
+	}
+	type test struct {
+		items           []testItem
+		inTransaction   bool
+		setupMockClient func(client *mocks.MockDynamoDBAPI, sizes *[]int)
+		want            want
+	}
+	errBatch := errors.New("batch error")
+	tests := map[string]test{
+		"happy_path/single_item": {
+			items: newTestItems(1),
+			want: want{
+				execs: []partiqlStatement{
+					{sql: insertSQL, vars: []interface{}{"Partition1", 0, "Item0"}},
+				},
+				rowsAffected: 1,
+			},
+		},
+		"happy_path/multiple_items": {
+			items: newTestItems(30),
+			setupMockClient: func(client *mocks.MockDynamoDBAPI, sizes *[]int) {
+				client.EXPECT().BatchExecuteStatement(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *dynamodb.BatchExecuteStatementInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchExecuteStatementOutput, error) {
+						*sizes = append(*sizes, len(in.Statements))
+						return &dynamodb.BatchExecuteStatementOutput{
+							Responses: make([]types.BatchStatementResponse, len(in.Statements)),
+						}, nil
+					}).Times(2)
+			},
+			want: want{
+				batchSizes:   []int{25, 5},
+				rowsAffected: 30,
+			},
+		},
+		"happy_path/multiple_items_in_transaction": {
+			items:         newTestItems(2),
+			inTransaction: true,
+			setupMockClient: func(client *mocks.MockDynamoDBAPI, sizes *[]int) {
+				client.EXPECT().ExecuteTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *dynamodb.ExecuteTransactionInput, _ ...func(*dynamodb.Options)) (*dynamodb.ExecuteTransactionOutput, error) {
+						*sizes = append(*sizes, len(in.TransactStatements))
+						return &dynamodb.ExecuteTransactionOutput{}, nil
+					}).Times(1)
+			},
+			want: want{
+				batchSizes: []int{2},
+			},
+		},
+		"unhappy_path/partial_failure": {
+			items: newTestItems(27),
+			setupMockClient: func(client *mocks.MockDynamoDBAPI, sizes *[]int) {
+				client.EXPECT().BatchExecuteStatement(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *dynamodb.BatchExecuteStatementInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchExecuteStatementOutput, error) {
+						*sizes = append(*sizes, len(in.Statements))
+						responses := make([]types.BatchStatementResponse, len(in.Statements))
+						if len(in.Statements) == 2 {
+							responses[1].Error = &types.BatchStatementError{
+								Code:    types.BatchStatementErrorCodeEnumDuplicateItem,
+								Message: aws.String("Duplicate primary key exists in table"),
+							}
+						}
+						return &dynamodb.BatchExecuteStatementOutput{Responses: responses}, nil
+					}).Times(2)
+			},
+			want: want{
+				batchSizes:   []int{25, 2},
+				rowsAffected: 26,
+				failures: []BatchCreateFailure{
+					{Index: 26, Code: "DuplicateItem", Message: "Duplicate primary key exists in table"},
+				},
+			},
+		},
+		"unhappy_path/batch_error": {
+			items: newTestItems(2),
+			setupMockClient: func(client *mocks.MockDynamoDBAPI, sizes *[]int) {
+				client.EXPECT().BatchExecuteStatement(gomock.Any(), gomock.Any()).
+					Return(nil, errBatch).Times(1)
+			},
+			want: want{
+				err: errBatch,
+			},
+		},
+	}
+	opts := []cmp.Option{
+		cmp.AllowUnexported(partiqlStatement{}),
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockDynamoDBAPI(ctrl)
+			var sizes []int
+			if setup := tt.setupMockClient; setup != nil {
+				setup(client, &sizes)
+			}
+			pool := &fakeConnPool{rowsAffected: 1}
+			db := openTestDB(t, pool, client, nil)
+			if tt.inTransaction {
+				db = db.Begin()
+			}
+
+			result := db.Create(&tt.items)
+			if tt.want.failures != nil {
+				var bce *BatchCreateError
+				if !errors.As(result.Error, &bce) {
+					t.Fatalf("Create() error = %v, want BatchCreateError", result.Error)
+				}
+				if diff := cmp.Diff(tt.want.failures, bce.Failures); diff != "" {
+					t.Errorf("Failures mismatch (-want +got):\n%s", diff)
+				}
+			} else if !errors.Is(result.Error, tt.want.err) {
+				t.Fatalf("Create() error = %v, want %v", result.Error, tt.want.err)
+			}
+			if tt.inTransaction {
+				if err := db.Commit().Error; err != nil {
+					t.Fatalf("Commit() error = %v", err)
+				}
+			}
+			if diff := cmp.Diff(tt.want.execs, pool.execs, opts...); diff != "" {
+				t.Errorf("executed statements mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.batchSizes, sizes); diff != "" {
+				t.Errorf("batch sizes mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.rowsAffected, result.RowsAffected); diff != "" {
+				t.Errorf("RowsAffected mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_create_withoutClient(t *testing.T) {
+	const insertSQL = `INSERT INTO "test_items" VALUE {'pk' : ?, 'sk' : ?, 'name' : ?}`
+	type want struct {
+		execs        []partiqlStatement
+		rowsAffected int64
+		failures     []BatchCreateFailure
+	}
+	type test struct {
+		err  error
+		want want
+	}
+	tests := map[string]test{
+		"happy_path": {
+			want: want{
+				execs: []partiqlStatement{
+					{sql: insertSQL, vars: []interface{}{"Partition1", 0, "Item0"}},
+					{sql: insertSQL, vars: []interface{}{"Partition1", 1, "Item1"}},
+				},
+				rowsAffected: 2,
+			},
+		},
+		"unhappy_path/failure": {
+			err: errors.New("exec error"),
+			want: want{
+				execs: []partiqlStatement{
+					{sql: insertSQL, vars: []interface{}{"Partition1", 0, "Item0"}},
+					{sql: insertSQL, vars: []interface{}{"Partition1", 1, "Item1"}},
+				},
+				failures: []BatchCreateFailure{
+					{Index: 0, Message: "exec error"},
+					{Index: 1, Message: "exec error"},
+				},
+			},
+		},
+	}
+	opts := []cmp.Option{
+		cmp.AllowUnexported(partiqlStatement{}),
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool := &fakeConnPool{rowsAffected: 1, err: tt.err}
+			db := openTestDB(t, pool, nil, nil)
+
+			items := newTestItems(2)
+			result := db.Create(&items)
+			var bce *BatchCreateError
+			if errors.As(result.Error, &bce) {
+				if diff := cmp.Diff(tt.want.failures, bce.Failures); diff != "" {
+					t.Errorf("Failures mismatch (-want +got):\n%s", diff)
+				}
+			} else if result.Error != nil || tt.want.failures != nil {
+				t.Fatalf("Create() error = %v, want failures %v", result.Error, tt.want.failures)
+			}
+			if diff := cmp.Diff(tt.want.execs, pool.execs, opts...); diff != "" {
+				t.Errorf("execs mismatch (-want +got):\n%s", diff)
+			}
+			if result.RowsAffected != tt.want.rowsAffected {
+				t.Errorf("RowsAffected = %d, want %d", result.RowsAffected, tt.want.rowsAffected)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package dynmgrm
+
+import (
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+)
+
+// compatibility check
+var (
+	_ clause.Interface       = (*conditionExpression)(nil)
+	_ gorm.StatementModifier = (*conditionExpression)(nil)
+)
+
+// conditionExpression is a clause.Interface that adds the conditions to the statement.
+type conditionExpression struct {
+	conditions []condition
+}
+
+// condition is a condition passed to Condition.
+type condition struct {
+	query interface{}
+	args  []interface{}
+}
+
+// Name returns the name of the clause.
+func (c conditionExpression) Name() string {
+	return "CONDITION"
+}
+
+// Build does nothing, since the conditions are built in WHERE clause, or as ConditionExpression of PutItem.
+func (c conditionExpression) Build(_ clause.Builder) {}
+
+// MergeClause merges the conditions into the clause.
+func (c conditionExpression) MergeClause(clause *clause.Clause) {
+	if exists, ok := clause.Expression.(conditionExpression); ok {
+		c.conditions = append(append([]condition{}, exists.conditions...), c.conditions...)
+	}
+	clause.Expression = c
+}
+
+// ModifyStatement adds the conditions to WHERE clause of the statement.
+func (c conditionExpression) ModifyStatement(stmt *gorm.Statement) {
+	for _, cond := range c.conditions {
+		if exprs := stmt.BuildCondition(cond.query, cond.args...); len(exprs) > 0 {
+			stmt.AddClause(clause.Where{Exprs: exprs})
+		}
+	}
+	// not by AddClause, which calls ModifyStatement again
+	cl := stmt.Clauses[c.Name()]
+	cl.Name = c.Name()
+	c.MergeClause(&cl)
+	stmt.Clauses[c.Name()] = cl
+}
+
+// Condition adds the condition on the attributes other than the keys to Update, Delete and Create.
+// If the item does not satisfy the condition, ErrConditionFailed is returned,
+// and if the item to be updated or deleted does not exist, gorm.ErrRecordNotFound is returned.
+//
+// Create with Condition writes the item by PutItem only if the item does not exist, as INSERT does.
+// With Overwrite, it overwrites the existing item instead, if the item satisfies the condition.
+//
+// e.g.
+//
+//	db.Model(&order).Clauses(dynmgrm.Condition(`status = ?`, "pending")).Update("status", "shipped")
+//	db.Clauses(dynmgrm.Overwrite(), dynmgrm.Condition(`status = ?`, "canceled")).Create(&order)
+func Condition(query interface{}, args ...interface{}) conditionExpression {
+	return conditionExpression{conditions: []condition{{query: query, args: args}}}
+}
+
+// conditionsOf returns the conditions of the statement.
+func conditionsOf(stmt *gorm.Statement) ([]condition, bool) {
+	c, ok := stmt.Clauses["CONDITION"]
+	if !ok {
+		return nil, false
+	}
+	expr, ok := c.Expression.(conditionExpression)
+	return expr.conditions, ok && len(expr.conditions) > 0
+}
+
+// overwriteExpression is a clause.Interface that allows Create to overwrite the existing item.
+// It is never built.
+type overwriteExpression struct{}
+
+// Name returns the name of the clause.
+func (o overwriteExpression) Name() string {
+	return "OVERWRITE"
+}
+
+// Build does nothing, since the clause is not a part of the statement.
+func (o overwriteExpression) Build(clause.Builder) {}
+
+// MergeClause merges the overwriteExpression into the clause.
+func (o overwriteExpression) MergeClause(clause *clause.Clause) {
+	clause.Expression = o
+}
+
+// Overwrite allows Create to overwrite the existing item by PutItem.
+// With Condition, the existing item is overwritten only if it satisfies the condition.
+//
+// e.g. db.Clauses(dynmgrm.Overwrite(), dynmgrm.Condition(`status = ?`, "canceled")).Create(&order)
+func Overwrite() overwriteExpression {
+	return overwriteExpression{}
+}
+
+// overwrites reports whether the statement overwrites the existing item.
+func overwrites(stmt *gorm.Statement) bool {
+	_, ok := stmt.Clauses[overwriteExpression{}.Name()]
+	return ok
+}
+
+// execConditional executes the UPDATE/DELETE statement with the conditions by ExecuteStatement,
+// which returns the existing item when the conditions fail, and scans the returned item if the statement has RETURNING.
+// RowsAffected of DELETE is the number of the deleted items, which is 0 if the item did not exist.
+//
+// In transactions begun by the user, the statement is added to the transaction.
+func execConditional(db *gorm.DB, returning bool) {
+	if db.DryRun || db.Error != nil {
+		return
+	}
+	if inTransaction(db) {
+		if returning {
+			db.AddError(ErrReturningInTransaction)
+			return
+		}
+		result, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		db.RowsAffected, _ = result.RowsAffected()
+		return
+	}
+	dialector, ok := db.Dialector.(*Dialector)
+	if !ok || dialector.client == nil {
+		db.AddError(ErrDynmgrmAreNotSupported)
+		return
+	}
+	statement := db.Statement.SQL.String()
+	params, err := partiqlStatement{sql: statement, vars: db.Statement.Vars}.parameters()
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	// DELETE succeeds without the item, e.g. with IS MISSING, so it returns the deleted item to be counted
+	deletes := !returning && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(statement)), "DELETE")
+	if deletes {
+		statement += " RETURNING ALL OLD *"
+	}
+	input := &dynamodb.ExecuteStatementInput{
+		Statement:                           aws.String(statement),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+	if len(params) > 0 {
+		input.Parameters = params
+	}
+	output, err := dialector.client.ExecuteStatement(db.Statement.Context, input)
+	if err != nil {
+		db.AddError(conditionFailedError(err, true))
+		return
+	}
+	if deletes {
+		db.RowsAffected = int64(len(output.Items))
+		return
+	}
+	// UPDATE fails without the item, so it has updated the item
+	db.RowsAffected = 1
+	if returning {
+		gorm.Scan(&timeRows{Rows: newItemRows(output.Items)}, db, 0)
+	}
+}
+
+// conditionFailedError returns ErrConditionFailed if the error is caused by the conditions,
+// or gorm.ErrRecordNotFound if the item does not exist and missingAsNotFound is true.
+// The original error is kept reachable only for ErrConditionFailed, not to be translated to it by Translate.
+// The other errors are returned as they are.
+func conditionFailedError(err error, missingAsNotFound bool) error {
+	var ccfe *types.ConditionalCheckFailedException
+	if !errors.As(err, &ccfe) {
+		return err
+	}
+	if missingAsNotFound && len(ccfe.Item) == 0 {
+		return fmt.Errorf("%w: the item does not exist", gorm.ErrRecordNotFound)
+	}
+	return fmt.Errorf("%w: %w", ErrConditionFailed, err)
+}
+
+// createConditional writes the items by PutItem with the conditions.
+// Unless the statement overwrites the existing item, the item is written only if its partition key does not exist.
+//
+// Like upsert, it runs outside the default transaction of gorm, and is not supported in transactions begun by the user.
+func createConditional(db *gorm.DB) {
+	conditions, _ := conditionsOf(db.Statement)
+	if !overwrites(db.Statement) {
+		pk, _, ok := keysOf(db.Statement)
+		if !ok {
+			db.AddError(fmt.Errorf("%w: Condition of Create without the partition key", ErrDynmgrmAreNotSupported))
+			return
+		}
+		conditions = append([]condition{{query: `attribute_not_exists("` + pk + `")`}}, conditions...)
+	}
+	var condition *putCondition
+	if len(conditions) > 0 {
+		var err error
+		condition, err = newPutCondition(conditions)
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+	}
+	restore, ok := outsideDefaultTransaction(db, "Condition of Create")
+	if !ok {
+		return
+	}
+	defer restore()
+	putItems(db, condition)
+}
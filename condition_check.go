@@ -0,0 +1,43 @@
+package dynmgrm
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+// ErrConditionCheckOutsideTransaction occurs when Check is called outside transactions.
+var ErrConditionCheckOutsideTransaction = errors.New("condition checks are supported only in transactions")
+
+// Check adds the condition check of the item to the transaction, without modifying the item.
+// If the item does not satisfy the condition on commit, the whole transaction is canceled
+// with ConditionalCheckFailed of the check in *TransactionCanceledError.
+//
+// The item is identified by the primary key of model, and query is the condition on its attributes.
+//
+// e.g.
+//
+//	db.Transaction(func(tx *gorm.DB) error {
+//		if err := dynmgrm.Check(tx, &Account{ID: "Account1"}, `status = ?`, "active"); err != nil {
+//			return err
+//		}
+//		return tx.Model(&Balance{ID: "Account1"}).Update("amount", gorm.Expr("amount - ?", 100)).Error
+//	})
+func Check(tx *gorm.DB, model interface{}, query interface{}, args ...interface{}) error {
+	if _, ok := tx.Statement.ConnPool.(*driverTransaction); ok {
+		return fmt.Errorf("%w: Check without the DynamoDB client", ErrDynmgrmAreNotSupported)
+	}
+	t, ok := tx.Statement.ConnPool.(*transaction)
+	if !ok {
+		return ErrConditionCheckOutsideTransaction
+	}
+	stmt := tx.Session(&gorm.Session{NewDB: true, DryRun: true}).
+		Model(model).
+		Where(query, args...).
+		Find(model)
+	if stmt.Error != nil {
+		return stmt.Error
+	}
+	_, err := t.ExecContext(tx.Statement.Context, "EXISTS("+stmt.Statement.SQL.String()+")", stmt.Statement.Vars...)
+	return err
+}
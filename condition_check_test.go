@@ -0,0 +1,140 @@
+package dynmgrm
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/miyamo2/dynmgrm/internal/mocks"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	type want struct {
+		statements []types.ParameterizedStatement
+		codes      []string
+		err        error
+	}
+	type test struct {
+		fn   func(tx *gorm.DB) error
+		err  error
+		want want
+	}
+	checkAndUpdate := func(tx *gorm.DB) error {
+		if err := Check(tx, &testItem{PK: "Partition1", SK: 1}, `name = ?`, "Item1"); err != nil {
+			return err
+		}
+		return tx.Model(&testItem{PK: "Partition1", SK: 2}).Update("name", "Item2").Error
+	}
+	statements := []types.ParameterizedStatement{
+		{
+			Statement: aws.String(`EXISTS(SELECT * FROM "test_items" WHERE name = ? AND "pk" = ? AND "sk" = ?)`),
+			Parameters: []types.AttributeValue{
+				&types.AttributeValueMemberS{Value: "Item1"},
+				&types.AttributeValueMemberS{Value: "Partition1"},
+				&types.AttributeValueMemberN{Value: "1"},
+			},
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		},
+		{
+			Statement: aws.String(`UPDATE "test_items" SET "name"=? WHERE "pk" = ? AND "sk" = ?`),
+			Parameters: []types.AttributeValue{
+				&types.AttributeValueMemberS{Value: "Item2"},
+				&types.AttributeValueMemberS{Value: "Partition1"},
+				&types.AttributeValueMemberN{Value: "2"},
+			},
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		},
+	}
+	tests := map[string]test{
+		"happy_path/satisfied": {
+			fn: checkAndUpdate,
+			want: want{
+				statements: statements,
+			},
+		},
+		"unhappy_path/not_satisfied": {
+			fn: checkAndUpdate,
+			err: &types.TransactionCanceledException{
+				CancellationReasons: []types.CancellationReason{
+					{Code: aws.String("ConditionalCheckFailed")},
+					{Code: aws.String("None")},
+				},
+			},
+			want: want{
+				statements: statements,
+				codes:      []string{"ConditionalCheckFailed", "None"},
+				err:        &TransactionCanceledError{},
+			},
+		},
+		"unhappy_path/after_read": {
+			fn: func(tx *gorm.DB) error {
+				if err := tx.Where(`pk = ? AND sk = ?`, "Partition1", 1).Find(&[]testItem{}).Error; err != nil {
+					return err
+				}
+				return Check(tx, &testItem{PK: "Partition1", SK: 1}, `name = ?`, "Item1")
+			},
+			want: want{
+				err: ErrMixedReadWriteTransaction,
+			},
+		},
+	}
+	opts := []cmp.Option{
+		cmpopts.IgnoreUnexported(
+			types.ParameterizedStatement{},
+			types.AttributeValueMemberS{},
+			types.AttributeValueMemberN{},
+		),
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockDynamoDBAPI(ctrl)
+			var got []types.ParameterizedStatement
+			if tt.want.statements != nil {
+				client.EXPECT().ExecuteTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *dynamodb.ExecuteTransactionInput, _ ...func(*dynamodb.Options)) (*dynamodb.ExecuteTransactionOutput, error) {
+						got = in.TransactStatements
+						return &dynamodb.ExecuteTransactionOutput{}, tt.err
+					}).Times(1)
+			}
+			db := openTestDB(t, &fakeConnPool{}, client, nil)
+
+			err := db.Transaction(tt.fn)
+			var tce *TransactionCanceledError
+			switch want := tt.want.err.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("Transaction() error = %v, want nil", err)
+				}
+			case *TransactionCanceledError:
+				if !errors.As(err, &tce) {
+					t.Fatalf("Transaction() error = %v, want TransactionCanceledError", err)
+				}
+				if diff := cmp.Diff(tt.want.codes, tce.Codes()); diff != "" {
+					t.Errorf("codes mismatch (-want +got):\n%s", diff)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("Transaction() error = %v, want %v", err, want)
+				}
+			}
+			if diff := cmp.Diff(tt.want.statements, got, opts...); diff != "" {
+				t.Errorf("statements mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheck_outsideTransaction(t *testing.T) {
+	db := openTestDB(t, &fakeConnPool{}, nil, nil)
+	err := Check(db, &testItem{PK: "Partition1", SK: 1}, `name = ?`, "Item1")
+	if !errors.Is(err, ErrConditionCheckOutsideTransaction) {
+		t.Fatalf("Check() error = %v, want %v", err, ErrConditionCheckOutsideTransaction)
+	}
+}
@@ -0,0 +1,199 @@
+package dynmgrm
+
+import (
+	"gorm.io/gorm/clause"
+)
+
+// compatibility check
+var (
+	_ clause.Expression                = (*conditionFunction)(nil)
+	_ clause.Expression                = (*sizeComparison)(nil)
+	_ clause.Expression                = (*missing)(nil)
+	_ clause.NegationExpressionBuilder = (*missing)(nil)
+	_ clause.Expression                = (*between)(nil)
+)
+
+// AttributeValueType is the data type of the attribute, that is passed to AttributeType.
+type AttributeValueType string
+
+const (
+	TypeString    AttributeValueType = "S"
+	TypeNumber    AttributeValueType = "N"
+	TypeBinary    AttributeValueType = "B"
+	TypeBoolean   AttributeValueType = "BOOL"
+	TypeNull      AttributeValueType = "NULL"
+	TypeList      AttributeValueType = "L"
+	TypeMap       AttributeValueType = "M"
+	TypeStringSet AttributeValueType = "SS"
+	TypeNumberSet AttributeValueType = "NS"
+	TypeBinarySet AttributeValueType = "BS"
+)
+
+// writeConditionColumn writes the column of the condition,
+// and adds ErrInvalidColumnName to the builder if the column contains invalid characters.
+func writeConditionColumn(builder clause.Builder, column string) bool {
+	if !isAttributePath(builder, column) && !reInvalidColumnName.MatchString(column) {
+		_ = builder.AddError(ErrInvalidColumnName)
+		return false
+	}
+	return writeAttribute(builder, column)
+}
+
+// conditionFunction is a clause.Expression for the functions that take the column and a value.
+type conditionFunction struct {
+	name   string
+	column string
+	value  interface{}
+}
+
+// Build builds the function.
+func (f conditionFunction) Build(builder clause.Builder) {
+	builder.WriteString(f.name + "(")
+	if !writeConditionColumn(builder, f.column) {
+		return
+	}
+	builder.WriteString(", ")
+	builder.AddVar(builder, f.value)
+	builder.WriteByte(')')
+}
+
+// BeginsWith returns a clause.Expression for `begins_with` function.
+//
+// e.g. db.Where(dynmgrm.BeginsWith("sk", "2024-")).Find(&items)
+func BeginsWith(column string, prefix string) clause.Expression {
+	return conditionFunction{name: "begins_with", column: column, value: prefix}
+}
+
+// Contains returns a clause.Expression for `contains` function,
+// which is true if the string contains the substring, or the set or the list contains the value.
+//
+// e.g. db.Where(dynmgrm.Contains("guest", "Alice")).Find(&events)
+func Contains(column string, value interface{}) clause.Expression {
+	return conditionFunction{name: "contains", column: column, value: value}
+}
+
+// AttributeType returns a clause.Expression for `attribute_type` function.
+//
+// e.g. db.Where(dynmgrm.AttributeType("tags", dynmgrm.TypeStringSet)).Find(&items)
+func AttributeType(column string, valueType AttributeValueType) clause.Expression {
+	return conditionFunction{name: "attribute_type", column: column, value: string(valueType)}
+}
+
+// size is the size of the attribute, that is compared by its methods.
+type size struct {
+	column string
+}
+
+// Size returns the size of the attribute for `size` function, which is compared by its methods.
+//
+// e.g. db.Where(dynmgrm.Size("tags").Gt(2)).Find(&items)
+func Size(column string) size {
+	return size{column: column}
+}
+
+// Eq returns a clause.Expression that the size is equal to n.
+func (s size) Eq(n int) clause.Expression {
+	return sizeComparison{column: s.column, operator: "=", value: n}
+}
+
+// Neq returns a clause.Expression that the size is not equal to n.
+func (s size) Neq(n int) clause.Expression {
+	return sizeComparison{column: s.column, operator: "<>", value: n}
+}
+
+// Gt returns a clause.Expression that the size is greater than n.
+func (s size) Gt(n int) clause.Expression {
+	return sizeComparison{column: s.column, operator: ">", value: n}
+}
+
+// Gte returns a clause.Expression that the size is greater than or equal to n.
+func (s size) Gte(n int) clause.Expression {
+	return sizeComparison{column: s.column, operator: ">=", value: n}
+}
+
+// Lt returns a clause.Expression that the size is less than n.
+func (s size) Lt(n int) clause.Expression {
+	return sizeComparison{column: s.column, operator: "<", value: n}
+}
+
+// Lte returns a clause.Expression that the size is less than or equal to n.
+func (s size) Lte(n int) clause.Expression {
+	return sizeComparison{column: s.column, operator: "<=", value: n}
+}
+
+// sizeComparison is a clause.Expression that compares the size of the attribute.
+type sizeComparison struct {
+	column   string
+	operator string
+	value    int
+}
+
+// Build builds the comparison.
+func (s sizeComparison) Build(builder clause.Builder) {
+	builder.WriteString("size(")
+	if !writeConditionColumn(builder, s.column) {
+		return
+	}
+	builder.WriteString(") " + s.operator + " ")
+	builder.AddVar(builder, s.value)
+}
+
+// missing is a clause.Expression for `MISSING` operator.
+type missing struct {
+	column string
+	not    bool
+}
+
+// Build builds the operator.
+func (m missing) Build(builder clause.Builder) {
+	if !writeConditionColumn(builder, m.column) {
+		return
+	}
+	if m.not {
+		builder.WriteString(" IS NOT MISSING")
+		return
+	}
+	builder.WriteString(" IS MISSING")
+}
+
+// NegationBuild builds the negated operator.
+func (m missing) NegationBuild(builder clause.Builder) {
+	missing{column: m.column, not: !m.not}.Build(builder)
+}
+
+// IsMissing returns a clause.Expression that the attribute does not exist.
+//
+// e.g. db.Where(dynmgrm.IsMissing("deleted_at")).Find(&items)
+func IsMissing(column string) clause.Expression {
+	return missing{column: column}
+}
+
+// IsNotMissing returns a clause.Expression that the attribute exists.
+func IsNotMissing(column string) clause.Expression {
+	return missing{column: column, not: true}
+}
+
+// between is a clause.Expression for `BETWEEN` operator.
+type between struct {
+	column string
+	lower  interface{}
+	upper  interface{}
+}
+
+// Build builds the operator.
+func (b between) Build(builder clause.Builder) {
+	if !writeConditionColumn(builder, b.column) {
+		return
+	}
+	builder.WriteString(" BETWEEN ")
+	builder.AddVar(builder, b.lower)
+	builder.WriteString(" AND ")
+	builder.AddVar(builder, b.upper)
+}
+
+// Between returns a clause.Expression that the attribute is between lower and upper, inclusive.
+//
+// e.g. db.Where(`pk = ?`, "Partition1").Where(dynmgrm.Between("sk", 1, 10)).Find(&items)
+func Between(column string, lower, upper interface{}) clause.Expression {
+	return between{column: column, lower: lower, upper: upper}
+}
@@ -0,0 +1,141 @@
+package dynmgrm
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"testing"
+)
+
+func TestConditionFunctions(t *testing.T) {
+	type want struct {
+		sql  string
+		vars []interface{}
+		err  error
+	}
+	type test struct {
+		query func(db *gorm.DB) *gorm.DB
+		want  want
+	}
+	tests := map[string]test{
+		"happy_path/begins_with": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Where(BeginsWith("name", "Item"))
+			},
+			want: want{
+				sql:  `SELECT * FROM "test_items" WHERE begins_with("name", ?)`,
+				vars: []interface{}{"Item"},
+			},
+		},
+		"happy_path/contains": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Where(Contains("guest", "Alice"))
+			},
+			want: want{
+				sql:  `SELECT * FROM "test_items" WHERE contains("guest", ?)`,
+				vars: []interface{}{"Alice"},
+			},
+		},
+		"happy_path/attribute_type": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Where(AttributeType("tags", TypeStringSet))
+			},
+			want: want{
+				sql:  `SELECT * FROM "test_items" WHERE attribute_type("tags", ?)`,
+				vars: []interface{}{"SS"},
+			},
+		},
+		"happy_path/size": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Where(Size("tags").Gt(2)).Where(Size("guest").Lte(10))
+			},
+			want: want{
+				sql:  `SELECT * FROM "test_items" WHERE size("tags") > ? AND size("guest") <= ?`,
+				vars: []interface{}{2, 10},
+			},
+		},
+		"happy_path/is_missing": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Where(IsMissing("deleted_at")).Where(IsNotMissing("name"))
+			},
+			want: want{
+				sql: `SELECT * FROM "test_items" WHERE "deleted_at" IS MISSING AND "name" IS NOT MISSING`,
+			},
+		},
+		"happy_path/between": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`pk = ?`, "Partition1").Where(Between("sk", 1, 10))
+			},
+			want: want{
+				sql:  `SELECT * FROM "test_items" WHERE pk = ? AND "sk" BETWEEN ? AND ?`,
+				vars: []interface{}{"Partition1", 1, 10},
+			},
+		},
+		"happy_path/nested_path": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Where(BeginsWith("profile.address.city", "To")).Where(IsMissing(Path("tags", 0)))
+			},
+			want: want{
+				sql:  `SELECT * FROM "test_items" WHERE begins_with("profile"."address"."city", ?) AND "tags"[0] IS MISSING`,
+				vars: []interface{}{"To"},
+			},
+		},
+		"happy_path/and_or_not": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Where(clause.Or(
+					clause.And(BeginsWith("name", "Item"), Size("tags").Eq(1)),
+					clause.Not(IsMissing("guest")),
+					clause.Not(Contains("guest", "Bob")),
+				))
+			},
+			want: want{
+				sql:  `SELECT * FROM "test_items" WHERE ((begins_with("name", ?) AND size("tags") = ?) OR "guest" IS NOT MISSING OR NOT contains("guest", ?))`,
+				vars: []interface{}{"Item", 1, "Bob"},
+			},
+		},
+		"happy_path/db_not": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Not(BeginsWith("name", "Item"))
+			},
+			want: want{
+				sql:  `SELECT * FROM "test_items" WHERE NOT begins_with("name", ?)`,
+				vars: []interface{}{"Item"},
+			},
+		},
+		"unhappy_path/invalid_column_name": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Where(BeginsWith("name==1", "Item"))
+			},
+			want: want{
+				err: ErrInvalidColumnName,
+			},
+		},
+		"unhappy_path/invalid_path": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Where(IsMissing("tags[-1]"))
+			},
+			want: want{
+				err: ErrInvalidAttributePath,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := openTestDB(t, &fakeConnPool{}, nil, nil).Session(&gorm.Session{DryRun: true})
+			result := tt.query(db).Find(&[]testItem{})
+			if !errors.Is(result.Error, tt.want.err) {
+				t.Fatalf("Find() error = %v, want %v", result.Error, tt.want.err)
+			}
+			if tt.want.err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.want.sql, result.Statement.SQL.String()); diff != "" {
+				t.Errorf("SQL mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.vars, result.Statement.Vars); diff != "" {
+				t.Errorf("Vars mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,688 @@
+package dynmgrm
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/miyamo2/dynmgrm/internal/mocks"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"testing"
+)
+
+func TestCondition(t *testing.T) {
+	type want struct {
+		inputs       []*dynamodb.ExecuteStatementInput
+		statements   []types.ParameterizedStatement
+		rowsAffected int64
+		err          error
+	}
+	type test struct {
+		operation func(db *gorm.DB) *gorm.DB
+		config    *gorm.Config
+		items     []map[string]types.AttributeValue
+		err       error
+		want      want
+	}
+	update := func(db *gorm.DB) *gorm.DB {
+		return db.Model(&testItem{PK: "Partition1", SK: 1}).
+			Clauses(Condition(`name = ?`, "Item1")).
+			Update("name", "Item2")
+	}
+	deleteItem := func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(Condition(`name = ?`, "Item1")).Delete(&testItem{PK: "Partition1", SK: 1})
+	}
+	updateInput := &dynamodb.ExecuteStatementInput{
+		Statement: aws.String(`UPDATE "test_items" SET "name"=? WHERE name = ? AND "pk" = ? AND "sk" = ?`),
+		Parameters: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "Item2"},
+			&types.AttributeValueMemberS{Value: "Item1"},
+			&types.AttributeValueMemberS{Value: "Partition1"},
+			&types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+	deleteInput := &dynamodb.ExecuteStatementInput{
+		Statement: aws.String(`DELETE FROM "test_items" WHERE name = ? AND "pk" = ? AND "sk" = ? RETURNING ALL OLD *`),
+		Parameters: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "Item1"},
+			&types.AttributeValueMemberS{Value: "Partition1"},
+			&types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+	errConditionNotMet := &types.ConditionalCheckFailedException{
+		Message: aws.String("The conditional request failed"),
+		Item: map[string]types.AttributeValue{
+			"pk":   &types.AttributeValueMemberS{Value: "Partition1"},
+			"sk":   &types.AttributeValueMemberN{Value: "1"},
+			"name": &types.AttributeValueMemberS{Value: "Item0"},
+		},
+	}
+	errItemMissing := &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	tests := map[string]test{
+		"happy_path/update": {
+			operation: update,
+			want: want{
+				inputs:       []*dynamodb.ExecuteStatementInput{updateInput},
+				rowsAffected: 1,
+			},
+		},
+		"happy_path/update_with_default_transaction": {
+			operation: update,
+			config:    &gorm.Config{},
+			want: want{
+				inputs:       []*dynamodb.ExecuteStatementInput{updateInput},
+				rowsAffected: 1,
+			},
+		},
+		"happy_path/delete": {
+			operation: deleteItem,
+			items: []map[string]types.AttributeValue{
+				{
+					"pk":   &types.AttributeValueMemberS{Value: "Partition1"},
+					"sk":   &types.AttributeValueMemberN{Value: "1"},
+					"name": &types.AttributeValueMemberS{Value: "Item1"},
+				},
+			},
+			want: want{
+				inputs:       []*dynamodb.ExecuteStatementInput{deleteInput},
+				rowsAffected: 1,
+			},
+		},
+		"happy_path/delete_item_missing": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(Condition(IsMissing("name"))).Delete(&testItem{PK: "Partition1", SK: 1})
+			},
+			want: want{
+				inputs: []*dynamodb.ExecuteStatementInput{
+					{
+						Statement: aws.String(`DELETE FROM "test_items" WHERE "name" IS MISSING AND "pk" = ? AND "sk" = ? RETURNING ALL OLD *`),
+						Parameters: []types.AttributeValue{
+							&types.AttributeValueMemberS{Value: "Partition1"},
+							&types.AttributeValueMemberN{Value: "1"},
+						},
+						ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+					},
+				},
+				rowsAffected: 0,
+			},
+		},
+		"happy_path/in_transaction": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				tx := update(db.Begin())
+				tx.AddError(tx.Commit().Error)
+				return tx
+			},
+			want: want{
+				statements: []types.ParameterizedStatement{
+					{
+						Statement:                           updateInput.Statement,
+						Parameters:                          updateInput.Parameters,
+						ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+					},
+				},
+			},
+		},
+		"unhappy_path/in_transaction_condition_not_met": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				tx := update(db.Begin())
+				tx.AddError(tx.Commit().Error)
+				return tx
+			},
+			err: &types.TransactionCanceledException{
+				CancellationReasons: []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}},
+			},
+			want: want{
+				statements: []types.ParameterizedStatement{
+					{
+						Statement:                           updateInput.Statement,
+						Parameters:                          updateInput.Parameters,
+						ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+					},
+				},
+				err: ErrConditionFailed,
+			},
+		},
+		"unhappy_path/update_condition_not_met": {
+			operation: update,
+			err:       errConditionNotMet,
+			want: want{
+				inputs: []*dynamodb.ExecuteStatementInput{updateInput},
+				err:    ErrConditionFailed,
+			},
+		},
+		"unhappy_path/update_item_missing": {
+			operation: update,
+			err:       errItemMissing,
+			want: want{
+				inputs: []*dynamodb.ExecuteStatementInput{updateInput},
+				err:    gorm.ErrRecordNotFound,
+			},
+		},
+		"unhappy_path/delete_condition_not_met": {
+			operation: deleteItem,
+			err:       errConditionNotMet,
+			want: want{
+				inputs: []*dynamodb.ExecuteStatementInput{deleteInput},
+				err:    ErrConditionFailed,
+			},
+		},
+		"unhappy_path/missing_where": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Model(&testItem{}).Clauses(Condition(map[string]interface{}{})).Update("name", "Item2")
+			},
+			want: want{
+				err: gorm.ErrMissingWhereClause,
+			},
+		},
+	}
+	opts := []cmp.Option{
+		cmpopts.IgnoreUnexported(
+			dynamodb.ExecuteStatementInput{},
+			types.AttributeValueMemberS{},
+			types.AttributeValueMemberN{},
+			types.ParameterizedStatement{},
+		),
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockDynamoDBAPI(ctrl)
+			var inputs []*dynamodb.ExecuteStatementInput
+			if tt.want.inputs != nil {
+				client.EXPECT().ExecuteStatement(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *dynamodb.ExecuteStatementInput, _ ...func(*dynamodb.Options)) (*dynamodb.ExecuteStatementOutput, error) {
+						inputs = append(inputs, in)
+						if tt.err != nil {
+							return nil, tt.err
+						}
+						return &dynamodb.ExecuteStatementOutput{Items: tt.items}, nil
+					}).Times(len(tt.want.inputs))
+			}
+			var statements []types.ParameterizedStatement
+			if tt.want.statements != nil {
+				client.EXPECT().ExecuteTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *dynamodb.ExecuteTransactionInput, _ ...func(*dynamodb.Options)) (*dynamodb.ExecuteTransactionOutput, error) {
+						statements = in.TransactStatements
+						return &dynamodb.ExecuteTransactionOutput{}, tt.err
+					}).Times(1)
+			}
+			db := openTestDB(t, &fakeConnPool{}, client, tt.config)
+
+			result := tt.operation(db)
+			if !errors.Is(result.Error, tt.want.err) {
+				t.Fatalf("error = %v, want %v", result.Error, tt.want.err)
+			}
+			if diff := cmp.Diff(tt.want.inputs, inputs, opts...); diff != "" {
+				t.Errorf("ExecuteStatementInput mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.statements, statements, opts...); diff != "" {
+				t.Errorf("statements mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.rowsAffected, result.RowsAffected); diff != "" {
+				t.Errorf("RowsAffected mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCondition_create(t *testing.T) {
+	type want struct {
+		input *dynamodb.PutItemInput
+		err   error
+	}
+	type test struct {
+		operation func(db *gorm.DB) *gorm.DB
+		err       error
+		want      want
+	}
+	item := map[string]types.AttributeValue{
+		"pk":   &types.AttributeValueMemberS{Value: "Partition1"},
+		"sk":   &types.AttributeValueMemberN{Value: "1"},
+		"name": &types.AttributeValueMemberS{Value: "Item1"},
+	}
+	create := func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(Overwrite(), Condition(`pk IS MISSING OR name IN ?`, []string{"Item0", "Item1"})).
+			Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+	}
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String("test_items"),
+		Item:                item,
+		ConditionExpression: aws.String(`(attribute_not_exists(#n0) OR #n1 IN (:v0, :v1))`),
+		ExpressionAttributeNames: map[string]string{
+			"#n0": "pk",
+			"#n1": "name",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":v0": &types.AttributeValueMemberS{Value: "Item0"},
+			":v1": &types.AttributeValueMemberS{Value: "Item1"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+	tests := map[string]test{
+		"happy_path": {
+			operation: create,
+			want: want{
+				input: input,
+			},
+		},
+		"happy_path/insert": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(Condition(`name <> ?`, "Item0")).
+					Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+			},
+			want: want{
+				input: &dynamodb.PutItemInput{
+					TableName:           aws.String("test_items"),
+					Item:                item,
+					ConditionExpression: aws.String(`(attribute_not_exists(#n0)) AND (#n1 <> :v0)`),
+					ExpressionAttributeNames: map[string]string{
+						"#n0": "pk",
+						"#n1": "name",
+					},
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":v0": &types.AttributeValueMemberS{Value: "Item0"},
+					},
+					ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+				},
+			},
+		},
+		"happy_path/overwrite_without_condition": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(Overwrite()).Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+			},
+			want: want{
+				input: &dynamodb.PutItemInput{
+					TableName: aws.String("test_items"),
+					Item:      item,
+				},
+			},
+		},
+		"unhappy_path/unsupported_condition": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(Condition(`name IS NULL`)).
+					Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+			},
+			want: want{
+				err: ErrDynmgrmAreNotSupported,
+			},
+		},
+		"happy_path/typed_expressions": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(Condition(BeginsWith("name", "Item")), Condition(Size("tags").Lt(3))).
+					Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+			},
+			want: want{
+				input: &dynamodb.PutItemInput{
+					TableName:           aws.String("test_items"),
+					Item:                item,
+					ConditionExpression: aws.String(`(attribute_not_exists(#n0)) AND (begins_with(#n1, :v0)) AND (size(#n2) < :v1)`),
+					ExpressionAttributeNames: map[string]string{
+						"#n0": "pk",
+						"#n1": "name",
+						"#n2": "tags",
+					},
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":v0": &types.AttributeValueMemberS{Value: "Item"},
+						":v1": &types.AttributeValueMemberN{Value: "3"},
+					},
+					ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+				},
+			},
+		},
+		"unhappy_path/bind_variables_mismatch": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(Condition(`name <> ?`, "Item0", "Item1")).
+					Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+			},
+			want: want{
+				err: ErrInvalidStatement,
+			},
+		},
+		"unhappy_path/condition_not_met": {
+			operation: create,
+			err:       &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")},
+			want: want{
+				input: input,
+				err:   ErrConditionFailed,
+			},
+		},
+		"unhappy_path/in_transaction": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return create(db.Begin())
+			},
+			want: want{
+				err: ErrDynmgrmAreNotSupported,
+			},
+		},
+		"unhappy_path/not_string": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(Condition(map[string]interface{}{"name": "Item1"})).
+					Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+			},
+			want: want{
+				err: ErrDynmgrmAreNotSupported,
+			},
+		},
+	}
+	opts := []cmp.Option{
+		cmpopts.IgnoreUnexported(
+			dynamodb.PutItemInput{},
+			types.AttributeValueMemberS{},
+			types.AttributeValueMemberN{},
+		),
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockDynamoDBAPI(ctrl)
+			var got *dynamodb.PutItemInput
+			if tt.want.input != nil {
+				client.EXPECT().PutItem(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+						got = in
+						if tt.err != nil {
+							return nil, tt.err
+						}
+						return &dynamodb.PutItemOutput{}, nil
+					}).Times(1)
+			}
+			db := openTestDB(t, &fakeConnPool{}, client, &gorm.Config{})
+
+			result := tt.operation(db)
+			if !errors.Is(result.Error, tt.want.err) {
+				t.Fatalf("Create() error = %v, want %v", result.Error, tt.want.err)
+			}
+			if diff := cmp.Diff(tt.want.input, got, opts...); diff != "" {
+				t.Errorf("PutItemInput mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPutCondition_translate(t *testing.T) {
+	type want struct {
+		expression string
+		names      map[string]string
+		values     map[string]types.AttributeValue
+	}
+	type test struct {
+		query string
+		args  []interface{}
+		want  want
+	}
+	tests := map[string]test{
+		"happy_path/literals": {
+			query: `status = 'pending' AND count >= 10 AND active = true`,
+			want: want{
+				expression: `#n0 = :v0 AND #n1 >= :v1 AND #n2 = :v2`,
+				names:      map[string]string{"#n0": "status", "#n1": "count", "#n2": "active"},
+				values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberS{Value: "pending"},
+					":v1": &types.AttributeValueMemberN{Value: "10"},
+					":v2": &types.AttributeValueMemberBOOL{Value: true},
+				},
+			},
+		},
+		"happy_path/functions": {
+			query: `attribute_type("x", 'N') AND NOT begins_with(name, ?) AND size(tags) != ?`,
+			args:  []interface{}{"Item", 2},
+			want: want{
+				expression: `attribute_type(#n0, :v0) AND NOT begins_with(#n1, :v1) AND size(#n2) <> :v2`,
+				names:      map[string]string{"#n0": "x", "#n1": "name", "#n2": "tags"},
+				values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberS{Value: "N"},
+					":v1": &types.AttributeValueMemberS{Value: "Item"},
+					":v2": &types.AttributeValueMemberN{Value: "2"},
+				},
+			},
+		},
+		"happy_path/negative_numbers": {
+			query: `balance > -10 AND (rate BETWEEN -0.5 AND 1.5) AND count IN (-1, 2)`,
+			want: want{
+				expression: `#n0 > :v0 AND (#n1 BETWEEN :v1 AND :v2) AND #n2 IN (:v3, :v4)`,
+				names:      map[string]string{"#n0": "balance", "#n1": "rate", "#n2": "count"},
+				values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberN{Value: "-10"},
+					":v1": &types.AttributeValueMemberN{Value: "-0.5"},
+					":v2": &types.AttributeValueMemberN{Value: "1.5"},
+					":v3": &types.AttributeValueMemberN{Value: "-1"},
+					":v4": &types.AttributeValueMemberN{Value: "2"},
+				},
+			},
+		},
+		"happy_path/paths": {
+			query: `"tags"[0] = ? AND profile."zip-code" IS MISSING`,
+			args:  []interface{}{"a"},
+			want: want{
+				expression: `#n0[0] = :v0 AND attribute_not_exists(#n1.#n2)`,
+				names:      map[string]string{"#n0": "tags", "#n1": "profile", "#n2": "zip-code"},
+				values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberS{Value: "a"},
+				},
+			},
+		},
+		"happy_path/missing_and_nested": {
+			query: `profile.city IS NOT MISSING AND profile.zip BETWEEN ? AND ?`,
+			args:  []interface{}{"100", "200"},
+			want: want{
+				expression: `attribute_exists(#n0.#n1) AND #n0.#n2 BETWEEN :v0 AND :v1`,
+				names:      map[string]string{"#n0": "profile", "#n1": "city", "#n2": "zip"},
+				values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberS{Value: "100"},
+					":v1": &types.AttributeValueMemberS{Value: "200"},
+				},
+			},
+		},
+	}
+	opts := []cmp.Option{
+		cmpopts.IgnoreUnexported(
+			types.AttributeValueMemberS{},
+			types.AttributeValueMemberN{},
+			types.AttributeValueMemberBOOL{},
+		),
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pc := &putCondition{names: map[string]string{}, values: map[string]types.AttributeValue{}}
+			got, err := pc.translate(tt.query, tt.args)
+			if err != nil {
+				t.Fatalf("translate() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want.expression, got); diff != "" {
+				t.Errorf("expression mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.names, pc.names); diff != "" {
+				t.Errorf("names mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.values, pc.values, opts...); diff != "" {
+				t.Errorf("values mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPutCondition_translate_unsupported(t *testing.T) {
+	tests := map[string]struct {
+		query string
+		args  []interface{}
+	}{
+		"is_null":           {query: `name IS NULL`},
+		"is_not_null":       {query: `name IS NOT NULL`},
+		"null":              {query: `name = NULL`},
+		"bare_null":         {query: `NULL`},
+		"arithmetic":        {query: `count - 1 > ?`, args: []interface{}{0}},
+		"addition":          {query: `count + ? > 0`, args: []interface{}{1}},
+		"like":              {query: `name LIKE 'Item%'`},
+		"unknown_function":  {query: `upper(name) = ?`, args: []interface{}{"ITEM"}},
+		"not_in":            {query: `name NOT IN ?`, args: []interface{}{[]string{"Item0"}}},
+		"adjacent_operands": {query: `name status = ?`, args: []interface{}{"pending"}},
+		"trailing_operator": {query: `name = ? AND`, args: []interface{}{"Item1"}},
+		"unterminated":      {query: `name = 'Item1`},
+		"invalid_number":    {query: `count = 1.2.3`},
+		"statement":         {query: `name = ?; DELETE FROM items`, args: []interface{}{"Item1"}},
+		"list_outside_in":   {query: `name = ?`, args: []interface{}{[]string{"Item0"}}},
+		"function_operand":  {query: `begins_with(size(name), ?)`, args: []interface{}{"Item"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pc := &putCondition{names: map[string]string{}, values: map[string]types.AttributeValue{}}
+			got, err := pc.translate(tt.query, tt.args)
+			if !errors.Is(err, ErrDynmgrmAreNotSupported) {
+				t.Errorf("translate() = %q, %v, want ErrDynmgrmAreNotSupported", got, err)
+			}
+		})
+	}
+}
+
+func TestPutCondition_translate_bindVariables(t *testing.T) {
+	tests := map[string]struct {
+		query string
+		args  []interface{}
+	}{
+		"extra":   {query: `name = ?`, args: []interface{}{"Item1", "Item2"}},
+		"missing": {query: `name = ? OR name = ?`, args: []interface{}{"Item1"}},
+		"none":    {query: `name = 'Item1'`, args: []interface{}{"Item1"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pc := &putCondition{names: map[string]string{}, values: map[string]types.AttributeValue{}}
+			got, err := pc.translate(tt.query, tt.args)
+			if !errors.Is(err, ErrInvalidStatement) {
+				t.Errorf("translate() = %q, %v, want ErrInvalidStatement", got, err)
+			}
+		})
+	}
+}
+
+func TestPutCondition_translateExpression(t *testing.T) {
+	type want struct {
+		expression string
+		names      map[string]string
+		values     map[string]types.AttributeValue
+		err        error
+	}
+	type test struct {
+		expr clause.Expression
+		want want
+	}
+	tests := map[string]test{
+		"happy_path/begins_with": {
+			expr: BeginsWith("name", "Item"),
+			want: want{
+				expression: `begins_with(#n0, :v0)`,
+				names:      map[string]string{"#n0": "name"},
+				values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberS{Value: "Item"},
+				},
+			},
+		},
+		"happy_path/size": {
+			expr: Size("tags").Gte(2),
+			want: want{
+				expression: `size(#n0) >= :v0`,
+				names:      map[string]string{"#n0": "tags"},
+				values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberN{Value: "2"},
+				},
+			},
+		},
+		"happy_path/is_missing_nested": {
+			expr: IsMissing("profile.city"),
+			want: want{
+				expression: `attribute_not_exists(#n0.#n1)`,
+				names:      map[string]string{"#n0": "profile", "#n1": "city"},
+				values:     map[string]types.AttributeValue{},
+			},
+		},
+		"happy_path/between": {
+			expr: Between("tags[1]", 1, 10),
+			want: want{
+				expression: `#n0[1] BETWEEN :v0 AND :v1`,
+				names:      map[string]string{"#n0": "tags"},
+				values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberN{Value: "1"},
+					":v1": &types.AttributeValueMemberN{Value: "10"},
+				},
+			},
+		},
+		"happy_path/and_or": {
+			expr: clause.And(clause.Eq{Column: "status", Value: "pending"}, clause.Or(IsNotMissing("name"), clause.IN{Column: clause.Column{Name: "sk"}, Values: []interface{}{1, 2}})),
+			want: want{
+				expression: `(#n0 = :v0 AND (attribute_exists(#n1) OR #n2 IN (:v1, :v2)))`,
+				names:      map[string]string{"#n0": "status", "#n1": "name", "#n2": "sk"},
+				values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberS{Value: "pending"},
+					":v1": &types.AttributeValueMemberN{Value: "1"},
+					":v2": &types.AttributeValueMemberN{Value: "2"},
+				},
+			},
+		},
+		"happy_path/not": {
+			expr: clause.Not(clause.Gt{Column: "count", Value: 10}, IsMissing("name"), BeginsWith("name", "Item")),
+			want: want{
+				expression: `(#n0 <= :v0 AND attribute_exists(#n1) AND NOT (begins_with(#n1, :v1)))`,
+				names:      map[string]string{"#n0": "count", "#n1": "name"},
+				values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberN{Value: "10"},
+					":v1": &types.AttributeValueMemberS{Value: "Item"},
+				},
+			},
+		},
+		"happy_path/expr": {
+			expr: clause.Expr{SQL: `name <> ?`, Vars: []interface{}{"Item0"}},
+			want: want{
+				expression: `#n0 <> :v0`,
+				names:      map[string]string{"#n0": "name"},
+				values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberS{Value: "Item0"},
+				},
+			},
+		},
+		"unhappy_path/nil": {
+			expr: clause.Eq{Column: "name", Value: nil},
+			want: want{err: ErrDynmgrmAreNotSupported},
+		},
+		"unhappy_path/like": {
+			expr: clause.Like{Column: "name", Value: "Item%"},
+			want: want{err: ErrDynmgrmAreNotSupported},
+		},
+		"unhappy_path/invalid_column": {
+			expr: clause.Eq{Column: "name = name OR name", Value: "Item1"},
+			want: want{err: ErrInvalidColumnName},
+		},
+	}
+	opts := []cmp.Option{
+		cmpopts.IgnoreUnexported(
+			types.AttributeValueMemberS{},
+			types.AttributeValueMemberN{},
+		),
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pc := &putCondition{names: map[string]string{}, values: map[string]types.AttributeValue{}}
+			got, err := pc.translateExpression(tt.expr)
+			if !errors.Is(err, tt.want.err) {
+				t.Fatalf("translateExpression() error = %v, want %v", err, tt.want.err)
+			}
+			if tt.want.err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.want.expression, got); diff != "" {
+				t.Errorf("expression mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.names, pc.names); diff != "" {
+				t.Errorf("names mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.values, pc.values, opts...); diff != "" {
+				t.Errorf("values mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
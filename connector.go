@@ -0,0 +1,68 @@
+package dynmgrm
+
+import (
+	"context"
+	"database/sql/driver"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/miyamo2/godynamo"
+	"sync"
+)
+
+// compatibility check
+var (
+	_ driver.Connector = (*connector)(nil)
+)
+
+// connectorLock serializes the connections opened with the registered aws.Config,
+// because godynamo refers to the one registered process-wide.
+var connectorLock sync.Mutex
+
+// connector is a driver.Connector that opens godynamo connections with the aws.Config.
+type connector struct {
+	dsn       string
+	awsConfig aws.Config
+	driver    godynamo.Driver
+}
+
+// Connect opens a new godynamo connection with the aws.Config.
+//
+// The aws.Config is registered to godynamo only while the connection is opened.
+// godynamo applies the HTTP client built from the DSN, so the HTTPClient of the aws.Config is ignored.
+func (c *connector) Connect(_ context.Context) (driver.Conn, error) {
+	connectorLock.Lock()
+	defer connectorLock.Unlock()
+	godynamo.RegisterAWSConfig(c.awsConfig)
+	defer godynamo.DeregisterAWSConfig()
+	return c.driver.Open(c.dsn)
+}
+
+// Driver returns the underlying godynamo driver.
+func (c *connector) Driver() driver.Driver {
+	return &c.driver
+}
+
+// awsConfigOf returns the aws.Config that builds a DynamoDB client configured as the client.
+func awsConfigOf(client *dynamodb.Client) aws.Config {
+	options := client.Options()
+	awsConfig := aws.Config{
+		Region:             options.Region,
+		Credentials:        options.Credentials,
+		BaseEndpoint:       options.BaseEndpoint,
+		APIOptions:         options.APIOptions,
+		Logger:             options.Logger,
+		ClientLogMode:      options.ClientLogMode,
+		RetryMaxAttempts:   options.RetryMaxAttempts,
+		RetryMode:          options.RetryMode,
+		DefaultsMode:       options.DefaultsMode,
+		RuntimeEnvironment: options.RuntimeEnvironment,
+		AppID:              options.AppID,
+		HTTPClient:         options.HTTPClient,
+	}
+	if retryer := options.Retryer; retryer != nil {
+		awsConfig.Retryer = func() aws.Retryer {
+			return retryer
+		}
+	}
+	return awsConfig
+}
@@ -0,0 +1,104 @@
+package dynmgrm
+
+import (
+	"context"
+	"database/sql"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+// newStubAWSConfig returns an aws.Config whose clients respond to every operation with the result without any requests.
+func newStubAWSConfig(t *testing.T, result interface{}, err error) aws.Config {
+	t.Helper()
+	return aws.Config{
+		Region: "ap-northeast-1",
+		APIOptions: []func(*middleware.Stack) error{
+			func(stack *middleware.Stack) error {
+				return stack.Initialize.Add(
+					middleware.InitializeMiddlewareFunc(
+						"Stub",
+						func(context.Context, middleware.InitializeInput, middleware.InitializeHandler) (
+							middleware.InitializeOutput, middleware.Metadata, error,
+						) {
+							return middleware.InitializeOutput{Result: result}, middleware.Metadata{}, err
+						}),
+					middleware.Before)
+			},
+		},
+	}
+}
+
+// queryPartitionKeys returns the pk of the items that the db responds to a SELECT statement.
+func queryPartitionKeys(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT * FROM "test_tables"`)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	defer rows.Close()
+
+	var got []string
+	for rows.Next() {
+		var pk string
+		if err := rows.Scan(&pk); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		got = append(got, pk)
+	}
+	return got
+}
+
+func TestConnector_Connect(t *testing.T) {
+	output := &dynamodb.ExecuteStatementOutput{
+		Items: []map[string]types.AttributeValue{
+			{"pk": &types.AttributeValueMemberS{Value: "Partition1"}},
+		},
+	}
+	type test struct {
+		awsConfig aws.Config
+	}
+	tests := map[string]test{
+		"happy_path/aws_config": {
+			awsConfig: newStubAWSConfig(t, output, nil),
+		},
+		"happy_path/dynamodb_client": {
+			awsConfig: awsConfigOf(dynamodb.NewFromConfig(newStubAWSConfig(t, output, nil))),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := sql.OpenDB(&connector{awsConfig: tt.awsConfig})
+			defer db.Close()
+
+			got := queryPartitionKeys(t, db)
+			if diff := cmp.Diff([]string{"Partition1"}, got); diff != "" {
+				t.Errorf("rows mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_awsConfigOf(t *testing.T) {
+	client := dynamodb.New(dynamodb.Options{
+		Region:       "eu-west-1",
+		BaseEndpoint: aws.String("http://localhost:8000"),
+		Retryer:      aws.NopRetryer{},
+	})
+	got := awsConfigOf(client)
+	if diff := cmp.Diff("eu-west-1", got.Region); diff != "" {
+		t.Errorf("Region mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(aws.String("http://localhost:8000"), got.BaseEndpoint); diff != "" {
+		t.Errorf("BaseEndpoint mismatch (-want +got):\n%s", diff)
+	}
+	if got.Retryer == nil {
+		t.Fatal("Retryer is nil")
+	}
+	if _, ok := got.Retryer().(aws.NopRetryer); !ok {
+		t.Errorf("Retryer = %T, want aws.NopRetryer", got.Retryer())
+	}
+}
@@ -0,0 +1,56 @@
+package dynmgrm
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// compatibility check
+var _ clause.Interface = (*consistentReadExpression)(nil)
+
+// consistentReadContextKey is the context key for the consistent read.
+type consistentReadContextKey struct{}
+
+// consistentReadExpression is a clause.Interface that makes the query run with strongly consistent reads.
+type consistentReadExpression struct{}
+
+// Name returns the name of the clause.
+func (c consistentReadExpression) Name() string {
+	return "WITH"
+}
+
+// Build builds the option for the strongly consistent reads of godynamo.
+func (c consistentReadExpression) Build(builder clause.Builder) {
+	builder.WriteString("ConsistentRead=true")
+}
+
+// MergeClause merges the consistentReadExpression into the clause.
+func (c consistentReadExpression) MergeClause(clause *clause.Clause) {
+	clause.Expression = c
+}
+
+// ConsistentRead makes the query run with strongly consistent reads.
+//
+// Secondary indexes except LSI do not support strongly consistent reads.
+func ConsistentRead() consistentReadExpression {
+	return consistentReadExpression{}
+}
+
+// ConsistentReadContext returns a copy of ctx with which the queries run with strongly consistent reads.
+//
+// e.g. db.WithContext(dynmgrm.ConsistentReadContext(ctx))
+func ConsistentReadContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistentReadContextKey{}, true)
+}
+
+// applyConsistentRead adds ConsistentRead to the query if the context of the statement requires it.
+func applyConsistentRead(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Context == nil {
+		return
+	}
+	if consistent, _ := db.Statement.Context.Value(consistentReadContextKey{}).(bool); !consistent {
+		return
+	}
+	db.Statement.AddClauseIfNotExists(ConsistentRead())
+}
@@ -0,0 +1,34 @@
+package dynmgrm_test
+
+import (
+	"context"
+	"github.com/miyamo2/dynmgrm"
+	"gorm.io/gorm"
+)
+
+func ExampleConsistentRead() {
+	db, err := gorm.Open(dynmgrm.New(), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+
+	result := TestTable{}
+	db.Clauses(dynmgrm.ConsistentRead()).
+		Where(`pk = ?`, "Partition1").
+		Find(&result)
+}
+
+func ExampleConsistentReadContext() {
+	db, err := gorm.Open(dynmgrm.New(), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+
+	session := db.Session(&gorm.Session{
+		Context: dynmgrm.ConsistentReadContext(context.Background()),
+	})
+
+	result := TestTable{}
+	session.Where(`pk = ?`, "Partition1").
+		Find(&result)
+}
@@ -0,0 +1,89 @@
+package dynmgrm
+
+import (
+	"context"
+	"database/sql"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestConsistentRead(t *testing.T) {
+	type test struct {
+		operation func(db *gorm.DB) *gorm.DB
+		want      string
+	}
+	tests := map[string]test{
+		"happy_path/clause": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(ConsistentRead()).Where(`pk = ?`, "Partition1").Find(&[]testItem{})
+			},
+			want: `SELECT * FROM "test_items" WHERE pk = ? WITH ConsistentRead=true`,
+		},
+		"happy_path/context": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.WithContext(ConsistentReadContext(context.Background())).
+					Where(`pk = ?`, "Partition1").Find(&[]testItem{})
+			},
+			want: `SELECT * FROM "test_items" WHERE pk = ? WITH ConsistentRead=true`,
+		},
+		"happy_path/session": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				session := db.Session(&gorm.Session{Context: ConsistentReadContext(context.Background())})
+				return session.Table("test_items").Where(`pk = ?`, "Partition1").Scan(&[]testItem{})
+			},
+			want: `SELECT * FROM "test_items" WHERE pk = ? WITH ConsistentRead=true`,
+		},
+		"happy_path/eventually_consistent": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`pk = ?`, "Partition1").Find(&[]testItem{})
+			},
+			want: `SELECT * FROM "test_items" WHERE pk = ?`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := openTestDB(t, &fakeConnPool{}, nil, &gorm.Config{SkipDefaultTransaction: true, DryRun: true})
+			got := tt.operation(db).Statement.SQL.String()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("SQL mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConsistentRead_ExecuteStatementInput(t *testing.T) {
+	var got *bool
+	awsConfig := aws.Config{
+		Region: "ap-northeast-1",
+		APIOptions: []func(*middleware.Stack) error{
+			func(stack *middleware.Stack) error {
+				return stack.Initialize.Add(
+					middleware.InitializeMiddlewareFunc(
+						"Capture",
+						func(_ context.Context, in middleware.InitializeInput, _ middleware.InitializeHandler) (
+							middleware.InitializeOutput, middleware.Metadata, error,
+						) {
+							if input, ok := in.Parameters.(*dynamodb.ExecuteStatementInput); ok {
+								got = input.ConsistentRead
+							}
+							return middleware.InitializeOutput{Result: &dynamodb.ExecuteStatementOutput{}}, middleware.Metadata{}, nil
+						}),
+					middleware.Before)
+			},
+		},
+	}
+	conn := sql.OpenDB(&connector{awsConfig: awsConfig})
+	defer conn.Close()
+	db := openTestDB(t, conn, nil, nil)
+
+	if err := db.Clauses(ConsistentRead()).Where(`pk = ?`, "Partition1").Find(&[]testItem{}).Error; err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if diff := cmp.Diff(aws.Bool(true), got); diff != "" {
+		t.Errorf("ConsistentRead mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -4,13 +4,19 @@
 package dynmgrm
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/miyamo2/godynamo"
 	"gorm.io/gorm/migrator"
 	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/callbacks"
@@ -46,14 +52,45 @@ const (
 	KeySchemaDataTypeBinary KeySchemaDataType = "binary"
 )
 
+// ZeroValuePolicy is the policy for writing zero values on insert.
+type ZeroValuePolicy string
+
+// Define ZeroValuePolicy
+const (
+	// ZeroValuePolicyOmitEmpty omits zero values and nil pointers, so that the attributes are MISSING.
+	ZeroValuePolicyOmitEmpty ZeroValuePolicy = "omitempty"
+	// ZeroValuePolicyAlways writes zero values as is, and nil pointers as NULL.
+	ZeroValuePolicyAlways ZeroValuePolicy = "always"
+	// ZeroValuePolicyNull writes nil pointers as NULL, and omits the other zero values.
+	ZeroValuePolicyNull ZeroValuePolicy = "null"
+)
+
+// ErrInvalidZeroValuePolicy occurs when the ZeroValuePolicy of the option or the tag is unknown.
+var ErrInvalidZeroValuePolicy = errors.New("invalid zero value policy")
+
+// validate returns ErrInvalidZeroValuePolicy if the policy is unknown.
+func (p ZeroValuePolicy) validate() error {
+	switch p {
+	case ZeroValuePolicyAlways, ZeroValuePolicyOmitEmpty, ZeroValuePolicyNull:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidZeroValuePolicy, p)
+}
+
 var (
-	queryClauses   = []string{"SELECT", "FROM", "WHERE"}
+	queryClauses   = []string{"SELECT", "FROM", "WHERE", "ORDER BY", "LIMIT", "WITH"}
 	createClauses  = []string{"INSERT", "VALUES"}
-	updateClauses  = []string{"UPDATE", "SET", "WHERE"}
-	deleteClauses  = []string{"DELETE", "FROM", "WHERE"}
+	updateClauses  = []string{"UPDATE", "SET", "WHERE", "RETURNING"}
+	deleteClauses  = []string{"DELETE", "FROM", "WHERE", "RETURNING"}
 	clauseBuilders = map[string]clause.ClauseBuilder{
-		"VALUES": toClauseBuilder(buildValuesClause),
-		"SET":    toClauseBuilder(buildSetClause),
+		"VALUES":    toClauseBuilder(buildValuesClause),
+		"SET":       toClauseBuilder(buildSetClause),
+		"WHERE":     toClauseBuilder(buildWhereClause),
+		"ORDER BY":  toClauseBuilder(buildOrderByClause),
+		"LIMIT":     toClauseBuilder(buildLimitClause),
+		"RETURNING": buildReturningClause,
+		"UPDATE":    buildScanGuardedClause,
+		"DELETE":    buildScanGuardedClause,
 	}
 )
 
@@ -65,6 +102,16 @@ type config struct {
 	endpoint string
 	timeout  int
 	conn     gorm.ConnPool
+	// awsConfig, credentials and client are registered to the driver with godynamo.RegisterAWSConfig
+	awsConfig       *aws.Config
+	credentials     aws.CredentialsProvider
+	client          *dynamodb.Client
+	removeNil       bool
+	zeroValuePolicy ZeroValuePolicy
+	cursorSecret    []byte
+	retryPolicy     *RetryPolicy
+	scanPolicy      ScanPolicy
+	indexSelection  bool
 }
 
 // DBOpener is the interface for opening a database.
@@ -78,6 +125,30 @@ type CallbacksRegisterer interface {
 	Register(db *gorm.DB, config *callbacks.Config)
 }
 
+// DynamoDBAPI is the interface for the DynamoDB operations that are issued without going through the driver.
+type DynamoDBAPI interface {
+	BatchExecuteStatement(
+		ctx context.Context,
+		params *dynamodb.BatchExecuteStatementInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.BatchExecuteStatementOutput, error)
+	ExecuteStatement(
+		ctx context.Context,
+		params *dynamodb.ExecuteStatementInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.ExecuteStatementOutput, error)
+	ExecuteTransaction(
+		ctx context.Context,
+		params *dynamodb.ExecuteTransactionInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.ExecuteTransactionOutput, error)
+	PutItem(
+		ctx context.Context,
+		params *dynamodb.PutItemInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.PutItemOutput, error)
+}
+
 // Dialector gorm dialector for DynamoDB
 type Dialector struct {
 	conn gorm.ConnPool
@@ -85,6 +156,21 @@ type Dialector struct {
 	dbOpener DBOpener
 	// callbacksRegisterer is used for testing
 	callbacksRegisterer CallbacksRegisterer
+	// client is used for the operations that are not supported by the driver.
+	// It is nil unless the client, aws.Config or credentials provider is specified.
+	client DynamoDBAPI
+	// removeNil is whether nil pointers in updates are built as REMOVE clause
+	removeNil bool
+	// zeroValuePolicy is the default policy for writing zero values on insert
+	zeroValuePolicy ZeroValuePolicy
+	// cursorSecret is the secret for signing the cursors of Page
+	cursorSecret []byte
+	// retryPolicy is the policy for retrying the throttled requests
+	retryPolicy *RetryPolicy
+	// scanPolicy is the policy for the statements that scan the whole table
+	scanPolicy ScanPolicy
+	// indexSelection is whether the queries use the secondary index whose keys the WHERE clause pins
+	indexSelection bool
 }
 
 // DialectorOption is the option for the DynamoDB dialector.
@@ -140,25 +226,139 @@ func WithConnection(conn gorm.ConnPool) func(*config) {
 	}
 }
 
+// WithAWSConfig sets the aws.Config for the DynamoDB connection.
+//
+// The region, access key ID, secret key, endpoint and timeout set by the other options take precedence over it.
+// It is registered to godynamo while each connection is opened, so do not combine it with godynamo.RegisterAWSConfig.
+func WithAWSConfig(awsConfig aws.Config) func(*config) {
+	return func(config *config) {
+		config.awsConfig = &awsConfig
+	}
+}
+
+// WithCredentialsProvider sets the credentials provider for the DynamoDB connection.
+//
+// It takes precedence over WithAccessKeyID, WithSecretKey and the credentials of WithAWSConfig.
+func WithCredentialsProvider(provider aws.CredentialsProvider) func(*config) {
+	return func(config *config) {
+		config.credentials = provider
+	}
+}
+
+// WithDynamoDBClient sets the exist DynamoDB client for the DynamoDB connection.
+//
+// The driver opens its connections with the configuration of the client, except for its HTTP client and endpoint resolver.
+// The operations that are not supported by the driver are issued by the client as is.
+func WithDynamoDBClient(client *dynamodb.Client) func(*config) {
+	return func(config *config) {
+		config.client = client
+	}
+}
+
+// WithRemoveNilOnSave sets whether nil pointer fields are removed from the item instead of being set to NULL,
+// when updating with Save, or with Update/Updates and nil pointers.
+//
+// Default: false
+func WithRemoveNilOnSave(removeNil bool) func(*config) {
+	return func(config *config) {
+		config.removeNil = removeNil
+	}
+}
+
+// WithZeroValuePolicy sets the default policy for writing zero values on insert.
+// It can be overridden for each field with the tag, e.g. `dynmgrm:"zero-value:always"`.
+//
+// Zero values of secondary index keys are always omitted unless the tag is specified,
+// since DynamoDB does not accept empty strings as them.
+//
+// Default: ZeroValuePolicyAlways
+func WithZeroValuePolicy(policy ZeroValuePolicy) func(*config) {
+	return func(config *config) {
+		config.zeroValuePolicy = policy
+	}
+}
+
+// WithCursorSecret sets the secret with which the cursors of Page are signed by HMAC-SHA256,
+// so that the cursors tampered by the clients are rejected with ErrInvalidCursor.
+//
+// Default: the cursors are not signed
+func WithCursorSecret(secret []byte) func(*config) {
+	return func(config *config) {
+		config.cursorSecret = secret
+	}
+}
+
 // Open returns a new DynamoDB dialector based on the DSN.
 //
+// The key `timeout`, which the former versions wrote, is accepted as `TimeoutMs`.
+//
 // e.g. "region=ap-northeast-1;AkId=<YOUR_ACCESS_KEY_ID>;SecretKey=<YOUR_SECRET_KEY>"
 func Open(dsn string) gorm.Dialector {
 	return &Dialector{
-		dbOpener:            dbOpener{dsn: dsn, driverName: DriverName},
+		dbOpener:            dbOpener{dsn: translateTimeoutKey(dsn), driverName: DriverName},
 		callbacksRegisterer: &callbacksRegisterer{},
 	}
 }
 
+// translateTimeoutKey rewrites the key `timeout` of the DSN to `TimeoutMs`, which godynamo reads,
+// unless the DSN has `TimeoutMs` as well. The keys are case-insensitive.
+func translateTimeoutKey(dsn string) string {
+	parts := strings.Split(dsn, ";")
+	index := -1
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "TIMEOUTMS":
+			return dsn
+		case "TIMEOUT":
+			index = i
+		}
+	}
+	if index < 0 {
+		return dsn
+	}
+	_, value, _ := strings.Cut(parts[index], "=")
+	parts[index] = "TimeoutMs=" + value
+	return strings.Join(parts, ";")
+}
+
 // New returns a new DynamoDB dialector with options.
 func New(option ...DialectorOption) gorm.Dialector {
 	conf := config{}
 	buildConfig(&conf, option...)
-	return &Dialector{
+	dsn := parseConnectionString(conf)
+	dialector := &Dialector{
 		conn:                conf.conn,
-		dbOpener:            dbOpener{dsn: parseConnectionString(conf), driverName: DriverName},
+		dbOpener:            dbOpener{dsn: dsn, driverName: DriverName},
 		callbacksRegisterer: &callbacksRegisterer{},
+		removeNil:           conf.removeNil,
+		zeroValuePolicy:     conf.zeroValuePolicy,
+		cursorSecret:        conf.cursorSecret,
+		scanPolicy:          conf.scanPolicy,
+		indexSelection:      conf.indexSelection,
 	}
+	awsConfig := newAWSConfig(conf)
+	if awsConfig != nil {
+		dialector.client = newDynamoDBClient(conf, *awsConfig)
+	}
+	if conf.retryPolicy != nil {
+		dialector.retryPolicy = conf.retryPolicy
+		if dialector.client != nil {
+			dialector.client = &retryClient{DynamoDBAPI: dialector.client, policy: *conf.retryPolicy}
+		}
+		if awsConfig == nil {
+			// the client that the driver builds from the DSN is configured with the registered aws.Config as well
+			awsConfig = &aws.Config{}
+		}
+		// the standard retryer would retry the statements that RetryPolicy does not
+		awsConfig.Retryer = func() aws.Retryer {
+			return aws.NopRetryer{}
+		}
+	}
+	if awsConfig != nil {
+		dialector.dbOpener = dbOpener{dsn: dsn, driverName: DriverName, awsConfig: awsConfig}
+	}
+	return dialector
 }
 
 func buildConfig(conf *config, option ...DialectorOption) {
@@ -167,6 +367,46 @@ func buildConfig(conf *config, option ...DialectorOption) {
 	}
 }
 
+// newDynamoDBClient returns the DynamoDB client for the operations that are not supported by the driver.
+func newDynamoDBClient(config config, awsConfig aws.Config) *dynamodb.Client {
+	if config.client != nil {
+		return config.client
+	}
+	return dynamodb.NewFromConfig(awsConfig)
+}
+
+// newAWSConfig returns the aws.Config to be registered to the driver.
+// If neither client, aws.Config nor credentials provider are specified, it returns nil and the driver builds its own.
+func newAWSConfig(config config) *aws.Config {
+	if config.client != nil {
+		awsConfig := awsConfigOf(config.client)
+		return &awsConfig
+	}
+	if config.awsConfig == nil && config.credentials == nil {
+		return nil
+	}
+	awsConfig := aws.Config{}
+	if config.awsConfig != nil {
+		awsConfig = config.awsConfig.Copy()
+	}
+	if config.akId != "" || config.secret != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentialsProvider(config.akId, config.secret, "")
+	}
+	if config.credentials != nil {
+		awsConfig.Credentials = config.credentials
+	}
+	if config.region != "" {
+		awsConfig.Region = config.region
+	}
+	if config.endpoint != "" {
+		awsConfig.BaseEndpoint = aws.String(config.endpoint)
+	}
+	if config.timeout != 0 {
+		awsConfig.HTTPClient = awshttp.NewBuildableClient().WithTimeout(time.Duration(config.timeout) * time.Millisecond)
+	}
+	return &awsConfig
+}
+
 func parseConnectionString(config config) string {
 	dsnbuf := strings.Builder{}
 	if config.region != "" {
@@ -182,7 +422,7 @@ func parseConnectionString(config config) string {
 		writeConnectionParameter(&dsnbuf, "endpoint", config.endpoint)
 	}
 	if config.timeout != 0 {
-		writeConnectionParameter(&dsnbuf, "timeout", strconv.Itoa(config.timeout))
+		writeConnectionParameter(&dsnbuf, "TimeoutMs", strconv.Itoa(config.timeout))
 	}
 	return dsnbuf.String()
 }
@@ -201,6 +441,16 @@ func (dialector Dialector) Name() string {
 
 // Initialize initializes the DynamoDB connection.
 func (dialector Dialector) Initialize(db *gorm.DB) (err error) {
+	if dialector.zeroValuePolicy != "" {
+		if err := dialector.zeroValuePolicy.validate(); err != nil {
+			return err
+		}
+	}
+	if dialector.scanPolicy != "" {
+		if err := dialector.scanPolicy.validate(); err != nil {
+			return err
+		}
+	}
 	if dialector.conn != nil {
 		db.ConnPool = dialector.conn
 	} else {
@@ -210,6 +460,7 @@ func (dialector Dialector) Initialize(db *gorm.DB) (err error) {
 		}
 		db.ConnPool = conn
 	}
+	db.ConnPool = &connPool{ConnPool: db.ConnPool, client: dialector.client, retryPolicy: dialector.retryPolicy}
 	dialector.callbacksRegisterer.Register(
 		db,
 		&callbacks.Config{
@@ -281,7 +532,9 @@ func (dialector Dialector) Migrator(db *gorm.DB) gorm.Migrator {
 	}
 }
 
-// Translate it will translate the error to native gorm errors.
+// Translate it will translate the error to native gorm errors, or to the errors of dynmgrm.
+//
+// It is called when gorm.Config.TranslateError is true.
 func (dialector Dialector) Translate(err error) error {
 	switch {
 	case errors.Is(err, godynamo.ErrTxCommitting),
@@ -291,12 +544,13 @@ func (dialector Dialector) Translate(err error) error {
 		errors.Is(err, godynamo.ErrNoTx):
 		return gorm.ErrInvalidTransaction
 	}
-	return err
+	return translateAPIError(err)
 }
 
 type dbOpener struct {
 	dsn        string
 	driverName string
+	awsConfig  *aws.Config
 }
 
 func (o dbOpener) DSN() string {
@@ -308,6 +562,9 @@ func (o dbOpener) DriverName() string {
 }
 
 func (o dbOpener) Apply() (*sql.DB, error) {
+	if o.awsConfig != nil {
+		return sql.OpenDB(&connector{dsn: o.DSN(), awsConfig: *o.awsConfig}), nil
+	}
 	return sql.Open(o.DriverName(), o.DSN())
 }
 
@@ -315,4 +572,22 @@ type callbacksRegisterer struct{}
 
 func (c *callbacksRegisterer) Register(db *gorm.DB, config *callbacks.Config) {
 	callbacks.RegisterDefaultCallbacks(db, config)
+	db.Callback().Create().Replace("gorm:create", create(config))
+	db.Callback().Query().Replace("gorm:query", query)
+	db.Callback().Update().Replace("gorm:update", countUpdates(update(config)))
+	db.Callback().Update().After("gorm:commit_or_rollback_transaction").
+		Register("dynmgrm:report_committed_updates", reportCommittedUpdates)
+	db.Callback().Delete().Replace("gorm:delete", deleteItems(config))
+	db.Callback().Query().Before("gorm:query").Register("dynmgrm:consistent_read", applyConsistentRead)
+	db.Callback().Row().Before("gorm:row").Register("dynmgrm:consistent_read", applyConsistentRead)
+	db.Callback().Query().Before("gorm:query").After("dynmgrm:consistent_read").
+		Register("dynmgrm:resolve_secondary_index", resolveSecondaryIndex)
+	db.Callback().Row().Before("gorm:row").After("dynmgrm:consistent_read").
+		Register("dynmgrm:resolve_secondary_index", resolveSecondaryIndex)
+
+	db.Callback().Create().Before("gorm:create").Register("dynmgrm:init_version", initVersion)
+	db.Callback().Update().Before("gorm:update").Register("dynmgrm:guard_version", guardVersion)
+	db.Callback().Update().After("dynmgrm:report_committed_updates").Register("dynmgrm:check_version", checkVersion(true))
+	db.Callback().Delete().Before("gorm:delete").Register("dynmgrm:guard_version", guardVersion)
+	db.Callback().Delete().After("gorm:commit_or_rollback_transaction").Register("dynmgrm:check_version", checkVersion(false))
 }
@@ -1,6 +1,9 @@
 package dynmgrm_test
 
 import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/miyamo2/dynmgrm"
 	"github.com/miyamo2/sqldav"
 	"gorm.io/gorm"
@@ -84,6 +87,21 @@ func ExampleNew_withTimeout() {
 	gorm.Open(dynmgrm.New(dynmgrm.WithTimeout(30000)))
 }
 
+func ExampleNew_withAWSConfig() {
+	gorm.Open(dynmgrm.New(dynmgrm.WithAWSConfig(aws.Config{Region: "ap-northeast-1"})))
+}
+
+func ExampleNew_withCredentialsProvider() {
+	gorm.Open(dynmgrm.New(
+		dynmgrm.WithRegion("ap-northeast-1"),
+		dynmgrm.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("YourAccess", "YourSecretKey", ""))))
+}
+
+func ExampleNew_withDynamoDBClient() {
+	client := dynamodb.NewFromConfig(aws.Config{Region: "ap-northeast-1"})
+	gorm.Open(dynmgrm.New(dynmgrm.WithDynamoDBClient(client)))
+}
+
 func ExampleWithRegion() {
 	dynmgrm.WithRegion("ap-northeast-1")
 }
@@ -104,6 +122,30 @@ func ExampleWithTimeout() {
 	dynmgrm.WithTimeout(30000)
 }
 
+func ExampleWithAWSConfig() {
+	dynmgrm.WithAWSConfig(aws.Config{Region: "ap-northeast-1"})
+}
+
+func ExampleWithCredentialsProvider() {
+	dynmgrm.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("YourAccess", "YourSecretKey", ""))
+}
+
+func ExampleWithDynamoDBClient() {
+	dynmgrm.WithDynamoDBClient(dynamodb.NewFromConfig(aws.Config{Region: "ap-northeast-1"}))
+}
+
+func ExampleWithRemoveNilOnSave() {
+	dynmgrm.WithRemoveNilOnSave(true)
+}
+
+func ExampleWithZeroValuePolicy() {
+	dynmgrm.WithZeroValuePolicy(dynmgrm.ZeroValuePolicyOmitEmpty)
+}
+
+func ExampleWithScanPolicy() {
+	dynmgrm.WithScanPolicy(dynmgrm.ScanPolicyDeny)
+}
+
 func ExampleOpen() {
 	gorm.Open(dynmgrm.Open("region=ap-northeast-1;AkId=YourAccessKeyID;SecretKey=YourSecretKey"))
 }
@@ -3,6 +3,12 @@ package dynmgrm
 import (
 	"database/sql"
 	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
 	"github.com/miyamo2/dynmgrm/internal/mocks"
 	"github.com/miyamo2/godynamo"
 	"go.uber.org/mock/gomock"
@@ -22,8 +28,15 @@ func TestNew(t *testing.T) {
 	}
 
 	type want struct {
-		dsn  string
-		conn gorm.ConnPool
+		dsn            string
+		conn           gorm.ConnPool
+		client         bool
+		region         string
+		removeNil      bool
+		policy         ZeroValuePolicy
+		scanPolicy     ScanPolicy
+		indexSelection bool
+		nopRetryer     bool
 	}
 
 	type test struct {
@@ -87,7 +100,7 @@ func TestNew(t *testing.T) {
 				},
 			},
 			want: want{
-				dsn: "timeout=1000",
+				dsn: "TimeoutMs=1000",
 			},
 		},
 		"happy_path/with_connection": {
@@ -101,6 +114,159 @@ func TestNew(t *testing.T) {
 				conn: &sql.DB{},
 			},
 		},
+		"happy_path/with_aws_config": {
+			args: args{
+				option: []DialectorOption{
+					WithAWSConfig(aws.Config{Region: "ap-northeast-1"}),
+				},
+			},
+			want: want{
+				dsn:    "",
+				client: true,
+				region: "ap-northeast-1",
+			},
+		},
+		"happy_path/with_aws_config_and_retry_policy": {
+			args: args{
+				option: []DialectorOption{
+					WithAWSConfig(aws.Config{Region: "ap-northeast-1"}),
+					WithRetryPolicy(RetryPolicy{}),
+				},
+			},
+			want: want{
+				dsn:        "",
+				client:     true,
+				region:     "ap-northeast-1",
+				nopRetryer: true,
+			},
+		},
+		"happy_path/with_aws_config_retryer_and_retry_policy": {
+			args: args{
+				option: []DialectorOption{
+					WithAWSConfig(aws.Config{
+						Region: "ap-northeast-1",
+						Retryer: func() aws.Retryer {
+							return retry.NewStandard()
+						},
+					}),
+					WithRetryPolicy(RetryPolicy{}),
+				},
+			},
+			want: want{
+				dsn:        "",
+				client:     true,
+				region:     "ap-northeast-1",
+				nopRetryer: true,
+			},
+		},
+		"happy_path/with_dynamodb_client_and_retry_policy": {
+			args: args{
+				option: []DialectorOption{
+					WithDynamoDBClient(dynamodb.New(dynamodb.Options{Region: "eu-west-1"})),
+					WithRetryPolicy(RetryPolicy{}),
+				},
+			},
+			want: want{
+				dsn:        "",
+				client:     true,
+				region:     "eu-west-1",
+				nopRetryer: true,
+			},
+		},
+		"happy_path/with_retry_policy": {
+			args: args{
+				option: []DialectorOption{
+					WithRetryPolicy(RetryPolicy{}),
+				},
+			},
+			want: want{
+				dsn:        "",
+				nopRetryer: true,
+			},
+		},
+		"happy_path/with_aws_config_and_region": {
+			args: args{
+				option: []DialectorOption{
+					WithAWSConfig(aws.Config{Region: "ap-northeast-1"}),
+					WithRegion("us-east-1"),
+				},
+			},
+			want: want{
+				dsn:    "region=us-east-1",
+				client: true,
+				region: "us-east-1",
+			},
+		},
+		"happy_path/with_credentials_provider": {
+			args: args{
+				option: []DialectorOption{
+					WithCredentialsProvider(credentials.NewStaticCredentialsProvider("ACCESS_KEY_ID", "SECRET", "")),
+					WithRegion("ap-northeast-1"),
+				},
+			},
+			want: want{
+				dsn:    "region=ap-northeast-1",
+				client: true,
+				region: "ap-northeast-1",
+			},
+		},
+		"happy_path/with_dynamodb_client": {
+			args: args{
+				option: []DialectorOption{
+					WithDynamoDBClient(dynamodb.New(dynamodb.Options{Region: "eu-west-1"})),
+					WithRegion("ap-northeast-1"),
+				},
+			},
+			want: want{
+				dsn:    "region=ap-northeast-1",
+				client: true,
+				region: "eu-west-1",
+			},
+		},
+		"happy_path/with_remove_nil_on_save": {
+			args: args{
+				option: []DialectorOption{
+					WithRemoveNilOnSave(true),
+				},
+			},
+			want: want{
+				dsn:       "",
+				removeNil: true,
+			},
+		},
+		"happy_path/with_zero_value_policy": {
+			args: args{
+				option: []DialectorOption{
+					WithZeroValuePolicy(ZeroValuePolicyAlways),
+				},
+			},
+			want: want{
+				dsn:    "",
+				policy: ZeroValuePolicyAlways,
+			},
+		},
+		"happy_path/with_scan_policy": {
+			args: args{
+				option: []DialectorOption{
+					WithScanPolicy(ScanPolicyDeny),
+				},
+			},
+			want: want{
+				dsn:        "",
+				scanPolicy: ScanPolicyDeny,
+			},
+		},
+		"happy_path/with_secondary_index_selection": {
+			args: args{
+				option: []DialectorOption{
+					WithSecondaryIndexSelection(true),
+				},
+			},
+			want: want{
+				dsn:            "",
+				indexSelection: true,
+			},
+		},
 	}
 
 	for name, tt := range tests {
@@ -120,6 +286,43 @@ func TestNew(t *testing.T) {
 			if !reflect.DeepEqual(tt.want.conn, d.conn) {
 				t.Errorf("conn expected: %v, actual: %v", tt.want.conn, d.conn)
 			}
+
+			if diff := cmp.Diff(tt.want.removeNil, d.removeNil); diff != "" {
+				t.Errorf("removeNil mismatch (-want +got): \n%v", diff)
+			}
+
+			if diff := cmp.Diff(tt.want.policy, d.zeroValuePolicy); diff != "" {
+				t.Errorf("zeroValuePolicy mismatch (-want +got): \n%v", diff)
+			}
+
+			if diff := cmp.Diff(tt.want.scanPolicy, d.scanPolicy); diff != "" {
+				t.Errorf("scanPolicy mismatch (-want +got): \n%v", diff)
+			}
+
+			if diff := cmp.Diff(tt.want.indexSelection, d.indexSelection); diff != "" {
+				t.Errorf("indexSelection mismatch (-want +got): \n%v", diff)
+			}
+
+			if tt.want.client != (d.client != nil) {
+				t.Fatalf("client expected: %v, actual: %v", tt.want.client, d.client)
+			}
+			awsConfig := d.dbOpener.(dbOpener).awsConfig
+			if (tt.want.client || tt.want.nopRetryer) != (awsConfig != nil) {
+				t.Fatalf("aws.Config expected: %v, actual: %v", tt.want.client || tt.want.nopRetryer, awsConfig)
+			}
+			if awsConfig == nil {
+				return
+			}
+			if diff := cmp.Diff(tt.want.region, awsConfig.Region); diff != "" {
+				t.Errorf("Region mismatch (-want +got): \n%v", diff)
+			}
+			nopRetryer := false
+			if awsConfig.Retryer != nil {
+				_, nopRetryer = awsConfig.Retryer().(aws.NopRetryer)
+			}
+			if diff := cmp.Diff(tt.want.nopRetryer, nopRetryer); diff != "" {
+				t.Errorf("NopRetryer mismatch (-want +got): \n%v", diff)
+			}
 		})
 
 	}
@@ -148,6 +351,22 @@ func TestOpen(t *testing.T) {
 				dsn: "region=ap-north-east1;akId=ACCESS_KEY_ID;secret",
 			},
 		},
+		"happy_path/timeout": {
+			args: args{
+				dsn: "region=ap-north-east1;Timeout=1000",
+			},
+			want: want{
+				dsn: "region=ap-north-east1;TimeoutMs=1000",
+			},
+		},
+		"happy_path/timeout_ms": {
+			args: args{
+				dsn: "region=ap-north-east1;timeout=1000;TimeoutMs=2000",
+			},
+			want: want{
+				dsn: "region=ap-north-east1;timeout=1000;TimeoutMs=2000",
+			},
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -424,6 +643,8 @@ func TestDialector_Initialize(t *testing.T) {
 	type test struct {
 		want                     error
 		conn                     gorm.ConnPool
+		zeroValuePolicy          ZeroValuePolicy
+		scanPolicy               ScanPolicy
 		setupDBOpener            func(*mocks.MockDBOpener)
 		setupCallbacksRegisterer func(*mocks.MockCallbacksRegisterer)
 	}
@@ -455,6 +676,26 @@ func TestDialector_Initialize(t *testing.T) {
 			},
 			want: errApply,
 		},
+		"unhappy_path/invalid-zero-value-policy": {
+			zeroValuePolicy: "omit",
+			setupDBOpener: func(do *mocks.MockDBOpener) {
+				do.EXPECT().Apply().Times(0)
+			},
+			setupCallbacksRegisterer: func(registerer *mocks.MockCallbacksRegisterer) {
+				registerer.EXPECT().Register(gomock.Any(), gomock.Any()).Times(0)
+			},
+			want: ErrInvalidZeroValuePolicy,
+		},
+		"unhappy_path/invalid-scan-policy": {
+			scanPolicy: "Deny",
+			setupDBOpener: func(do *mocks.MockDBOpener) {
+				do.EXPECT().Apply().Times(0)
+			},
+			setupCallbacksRegisterer: func(registerer *mocks.MockCallbacksRegisterer) {
+				registerer.EXPECT().Register(gomock.Any(), gomock.Any()).Times(0)
+			},
+			want: ErrInvalidScanPolicy,
+		},
 	}
 
 	for name, tt := range tests {
@@ -471,6 +712,8 @@ func TestDialector_Initialize(t *testing.T) {
 			dialector := Dialector{
 				dbOpener:            do,
 				callbacksRegisterer: cr,
+				zeroValuePolicy:     tt.zeroValuePolicy,
+				scanPolicy:          tt.scanPolicy,
 			}
 			if conn := tt.conn; conn != nil {
 				dialector.conn = conn
@@ -479,7 +722,7 @@ func TestDialector_Initialize(t *testing.T) {
 				Config: &gorm.Config{
 					ClauseBuilders: make(map[string]clause.ClauseBuilder),
 				},
-			}); !errors.Is(tt.want, err) {
+			}); !errors.Is(err, tt.want) {
 				t.Errorf("Initialize() error = %v, wantErr %v", err, tt.want)
 			}
 		})
@@ -488,11 +731,73 @@ func TestDialector_Initialize(t *testing.T) {
 
 func TestDialector_Translate(t *testing.T) {
 	type test struct {
-		args error
-		want error
+		args          error
+		want          error
+		keepsOriginal bool
 	}
 	errOther := errors.New("other")
+	errDuplicateItem := &types.DuplicateItemException{Message: aws.String("Duplicate primary key exists in table")}
+	errResourceNotFound := &types.ResourceNotFoundException{Message: aws.String("Requested resource not found")}
+	errThroughputExceeded := &types.ProvisionedThroughputExceededException{Message: aws.String("The level of configured provisioned throughput for the table was exceeded")}
+	errRequestLimitExceeded := &types.RequestLimitExceeded{Message: aws.String("Throughput exceeds the current throughput limit for your account")}
+	errThrottling := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate of requests exceeds the allowed throughput"}
+	errConditionalCheckFailed := &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	errValidation := &smithy.GenericAPIError{Code: "ValidationException", Message: "Statement wasn't well formed"}
+	errInternalServer := &types.InternalServerError{Message: aws.String("Internal server error")}
 	tests := map[string]test{
+		"happy_path/DuplicateItemException": {
+			args:          errDuplicateItem,
+			want:          gorm.ErrDuplicatedKey,
+			keepsOriginal: true,
+		},
+		"happy_path/ResourceNotFoundException": {
+			args:          errResourceNotFound,
+			want:          ErrTableNotFound,
+			keepsOriginal: true,
+		},
+		"happy_path/ProvisionedThroughputExceededException": {
+			args:          errThroughputExceeded,
+			want:          ErrThrottled,
+			keepsOriginal: true,
+		},
+		"happy_path/RequestLimitExceeded": {
+			args:          errRequestLimitExceeded,
+			want:          ErrThrottled,
+			keepsOriginal: true,
+		},
+		"happy_path/ThrottlingException": {
+			args:          errThrottling,
+			want:          ErrThrottled,
+			keepsOriginal: true,
+		},
+		"happy_path/ConditionalCheckFailedException": {
+			args:          errConditionalCheckFailed,
+			want:          ErrConditionFailed,
+			keepsOriginal: true,
+		},
+		"happy_path/ValidationException": {
+			args:          errValidation,
+			want:          ErrInvalidStatement,
+			keepsOriginal: true,
+		},
+		"happy_path/wrapped_by_operation_error": {
+			args: &smithy.OperationError{
+				ServiceID:     "DynamoDB",
+				OperationName: "ExecuteStatement",
+				Err:           errConditionalCheckFailed,
+			},
+			want:          ErrConditionFailed,
+			keepsOriginal: true,
+		},
+		"happy_path/already_translated": {
+			args:          conditionFailedError(errConditionalCheckFailed, false),
+			want:          ErrConditionFailed,
+			keepsOriginal: true,
+		},
+		"happy_path/other_api_error": {
+			args: errInternalServer,
+			want: errInternalServer,
+		},
 		"happy_path/ErrTxCommitting": {
 			args: godynamo.ErrInTx,
 			want: gorm.ErrInvalidTransaction,
@@ -529,6 +834,9 @@ func TestDialector_Translate(t *testing.T) {
 			if !errors.Is(err, tt.want) {
 				t.Errorf("Translate() error = %v, want %v", err, tt.want)
 			}
+			if tt.keepsOriginal && !errors.Is(err, tt.args) {
+				t.Errorf("Translate() error = %v, want to keep %v", err, tt.args)
+			}
 		})
 	}
 }
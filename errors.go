@@ -0,0 +1,48 @@
+package dynmgrm
+
+import (
+	"errors"
+	"fmt"
+	"github.com/aws/smithy-go"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTableNotFound occurs when the table or the index does not exist.
+	ErrTableNotFound = errors.New("table not found")
+	// ErrThrottled occurs when the request is throttled by DynamoDB.
+	ErrThrottled = errors.New("request throttled")
+	// ErrConditionFailed occurs when the condition of the statement is not satisfied.
+	ErrConditionFailed = errors.New("condition failed")
+	// ErrInvalidStatement occurs when DynamoDB rejects the statement as invalid,
+	// or when the bind variables of the condition of Create do not match its placeholders.
+	ErrInvalidStatement = errors.New("invalid statement")
+)
+
+// translateAPIError translates the error returned by DynamoDB to the one of gorm or dynmgrm.
+// The original error is kept reachable through errors.As.
+func translateAPIError(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	var translated error
+	switch apiErr.ErrorCode() {
+	case "DuplicateItemException":
+		translated = gorm.ErrDuplicatedKey
+	case "ResourceNotFoundException":
+		translated = ErrTableNotFound
+	case "ProvisionedThroughputExceededException", "RequestLimitExceeded", "ThrottlingException":
+		translated = ErrThrottled
+	case "ConditionalCheckFailedException":
+		translated = ErrConditionFailed
+	case "ValidationException":
+		translated = ErrInvalidStatement
+	default:
+		return err
+	}
+	if errors.Is(err, translated) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", translated, err)
+}
@@ -1,11 +1,15 @@
 package dynmgrm
 
 import (
+	"database/sql/driver"
 	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 	"reflect"
 	"slices"
+	"strconv"
 )
 
 // expressionBuilder is a function that builds a clause.Expression
@@ -27,6 +31,8 @@ func toClauseBuilder[T clause.Expression](xpBuilder expressionBuilder[T]) clause
 }
 
 // buildValuesClause builds VALUES clause
+//
+// Zero values are written according to the ZeroValuePolicy of each field.
 func buildValuesClause(values clause.Values, stmt *gorm.Statement) {
 	columns := values.Columns
 	if len(columns) <= 0 {
@@ -37,15 +43,21 @@ func buildValuesClause(values clause.Values, stmt *gorm.Statement) {
 
 	stmt.WriteString("VALUE ")
 	stmt.WriteByte('{')
-	prfl := stmt.Schema.PrimaryFieldDBNames
+	written := false
 	for i, column := range columns {
 		v := items[i]
-		if isZeroValue(v) && !slices.Contains[[]string](prfl, column.Name) {
+		omitted, err := isOmittedOnInsert(stmt, column.Name, v)
+		if err != nil {
+			stmt.AddError(err)
+			return
+		}
+		if omitted {
 			continue
 		}
-		if i > 0 {
+		if written {
 			stmt.WriteString(", ")
 		}
+		written = true
 		stmt.WriteString(fmt.Sprintf(`'%s'`, column.Name))
 		stmt.WriteString(" : ")
 
@@ -54,34 +66,167 @@ func buildValuesClause(values clause.Values, stmt *gorm.Statement) {
 	stmt.WriteByte('}')
 }
 
+// isOmittedOnInsert reports whether the value of the column is omitted from the item on insert.
+// The values of the primary key are never omitted.
+func isOmittedOnInsert(stmt *gorm.Statement, column string, v interface{}) (bool, error) {
+	if stmt.Schema != nil && slices.Contains(stmt.Schema.PrimaryFieldDBNames, column) {
+		return false, nil
+	}
+	defaultPolicy := ZeroValuePolicyAlways
+	if dialector, ok := stmt.DB.Dialector.(*Dialector); ok && dialector.zeroValuePolicy != "" {
+		defaultPolicy = dialector.zeroValuePolicy
+	}
+	policy, err := zeroValuePolicyOf(stmt.Schema, column, defaultPolicy)
+	if err != nil {
+		return false, err
+	}
+	return isOmitted(v, policy), nil
+}
+
+// zeroValuePolicyOf returns the ZeroValuePolicy of the column.
+// It returns ErrInvalidZeroValuePolicy if the tag of the column has an unknown one.
+func zeroValuePolicyOf(sch *schema.Schema, column string, defaultPolicy ZeroValuePolicy) (ZeroValuePolicy, error) {
+	if sch == nil {
+		return defaultPolicy, nil
+	}
+	field := sch.LookUpField(column)
+	if field == nil {
+		return defaultPolicy, nil
+	}
+	dTag := newDynmgrmTag(field.Tag)
+	if dTag.ZeroValuePolicySpecified {
+		if err := dTag.ZeroValuePolicy.validate(); err != nil {
+			return "", fmt.Errorf("%w of %s.%s", err, sch.Name, field.Name)
+		}
+		return dTag.ZeroValuePolicy, nil
+	}
+	if len(dTag.IndexProperty) > 0 {
+		// DynamoDB does not accept empty strings as secondary index keys
+		return ZeroValuePolicyOmitEmpty, nil
+	}
+	return defaultPolicy, nil
+}
+
+// isOmitted reports whether the value is omitted on insert by the policy.
+func isOmitted(v interface{}, policy ZeroValuePolicy) bool {
+	if isEmptySet(v) {
+		// DynamoDB does not accept empty sets
+		return true
+	}
+	switch policy {
+	case ZeroValuePolicyAlways:
+		return false
+	case ZeroValuePolicyNull:
+		return !isNil(v) && isZeroValue(v)
+	}
+	return isZeroValue(v)
+}
+
+// isEmptySet reports whether the value is written as a set without elements.
+func isEmptySet(v interface{}) bool {
+	valuer, ok := v.(driver.Valuer)
+	if !ok {
+		return false
+	}
+	av, err := valuer.Value()
+	if err != nil {
+		return false
+	}
+	switch av := av.(type) {
+	case *types.AttributeValueMemberSS:
+		return len(av.Value) == 0
+	case *types.AttributeValueMemberNS:
+		return len(av.Value) == 0
+	case *types.AttributeValueMemberBS:
+		return len(av.Value) == 0
+	}
+	return false
+}
+
+// isNil reports whether the value is nil or a nil pointer.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
 // buildSetClause builds SET clause
+//
+// Assignments of Remove, and of nil pointers if WithRemoveNilOnSave is enabled, are built as REMOVE clause.
+// If the version is guarded, it is incremented instead of being assigned.
 func buildSetClause(set clause.Set, stmt *gorm.Statement) {
 	if len(set) <= 0 {
 		return
 	}
 	prfl := stmt.Schema.PrimaryFieldDBNames
-	for idx, assignment := range set {
+	var versionField *schema.Field
+	if _, ok := versionGuardOf(stmt); ok {
+		versionField = versionFieldOf(stmt.Schema)
+	}
+	removeNil := false
+	if dialector, ok := stmt.DB.Dialector.(*Dialector); ok {
+		removeNil = dialector.removeNil
+	}
+	var removes []string
+	written := false
+	for _, assignment := range set {
 		asgcol := assignment.Column.Name
 		if slices.Contains[[]string](prfl, asgcol) {
 			continue
 		}
-		if idx > 0 {
+		if versionField != nil && asgcol == versionField.DBName {
+			continue
+		}
+		asgv := assignment.Value
+		if isRemoval(asgv, removeNil) {
+			removes = append(removes, asgcol)
+			continue
+		}
+		if written {
 			stmt.WriteByte(' ')
 		}
+		written = true
 		stmt.WriteString("SET ")
-		stmt.WriteQuoted(asgcol)
+		writeAttribute(stmt, asgcol)
 		stmt.WriteByte('=')
-		asgv := assignment.Value
 		switch asgv := asgv.(type) {
 		case functionForPartiQLUpdates:
-			valuer := asgv.bindVariable()
 			stmt.WriteString(asgv.expression(stmt.DB, asgcol))
-			stmt.AddVar(stmt, valuer)
-			stmt.WriteByte(')')
+			stmt.AddVar(stmt, asgv.bindVariable())
+			stmt.WriteString(asgv.closing(stmt.DB, asgcol))
 			continue
 		}
 		stmt.AddVar(stmt, asgv)
 	}
+	if versionField != nil {
+		if written {
+			stmt.WriteByte(' ')
+		}
+		written = true
+		stmt.WriteString("SET ")
+		stmt.WriteQuoted(versionField.DBName)
+		stmt.WriteByte('=')
+		stmt.WriteQuoted(versionField.DBName)
+		stmt.WriteString(" + 1")
+	}
+	for _, col := range removes {
+		if written {
+			stmt.WriteByte(' ')
+		}
+		written = true
+		stmt.WriteString("REMOVE ")
+		writeAttribute(stmt, col)
+	}
+}
+
+// isRemoval reports whether the assigned value removes the attribute.
+func isRemoval(v interface{}, removeNil bool) bool {
+	if _, ok := v.(removeAttribute); ok {
+		return true
+	}
+	return removeNil && v != nil && isNil(v)
 }
 
 func isZeroValue(v interface{}) bool {
@@ -90,3 +235,128 @@ func isZeroValue(v interface{}) bool {
 	}
 	return reflect.ValueOf(v).IsZero()
 }
+
+// buildWhereClause builds WHERE clause
+//
+// Since PartiQL for DynamoDB does not support them,
+// columns are written without the table name, and the tuples of IN are expanded into conditions.
+// The condition that the item is not soft deleted is built as `IS MISSING OR IS NULL`.
+func buildWhereClause(where clause.Where, stmt *gorm.Statement) {
+	if len(where.Exprs) <= 0 {
+		return
+	}
+	var softDeleteColumn string
+	if field := softDeleteFieldOf(stmt.Schema); field != nil {
+		softDeleteColumn = field.DBName
+	}
+	exprs := make([]clause.Expression, 0, len(where.Exprs))
+	for _, expr := range where.Exprs {
+		_, isIN := expr.(clause.IN)
+		expr = toPartiQLCondition(expr, softDeleteColumn)
+		if and, ok := expr.(clause.AndConditions); ok && isIN {
+			// expanded tuple of primary keys
+			exprs = append(exprs, and.Exprs...)
+			continue
+		}
+		exprs = append(exprs, expr)
+	}
+	stmt.WriteString("WHERE ")
+	clause.Where{Exprs: exprs}.Build(stmt)
+}
+
+// toPartiQLCondition converts the condition built by gorm to the one that PartiQL for DynamoDB supports.
+func toPartiQLCondition(expr clause.Expression, softDeleteColumn string) clause.Expression {
+	switch expr := expr.(type) {
+	case clause.Eq:
+		expr.Column = unqualifiedColumn(expr.Column)
+		if column, ok := expr.Column.(clause.Column); ok &&
+			softDeleteColumn != "" && column.Name == softDeleteColumn && isNilValue(expr.Value) {
+			return clause.Expr{SQL: "? IS MISSING OR ? IS NULL", Vars: []interface{}{column, column}}
+		}
+		return expr
+	case clause.Neq:
+		expr.Column = unqualifiedColumn(expr.Column)
+		return expr
+	case clause.Gt:
+		expr.Column = unqualifiedColumn(expr.Column)
+		return expr
+	case clause.Gte:
+		expr.Column = unqualifiedColumn(expr.Column)
+		return expr
+	case clause.Lt:
+		expr.Column = unqualifiedColumn(expr.Column)
+		return expr
+	case clause.Lte:
+		expr.Column = unqualifiedColumn(expr.Column)
+		return expr
+	case clause.IN:
+		columns, ok := expr.Column.([]clause.Column)
+		if !ok {
+			expr.Column = unqualifiedColumn(expr.Column)
+			return expr
+		}
+		tuples := make([]clause.Expression, 0, len(expr.Values))
+		for _, v := range expr.Values {
+			values, ok := v.([]interface{})
+			if !ok || len(values) != len(columns) {
+				return expr
+			}
+			eqs := make([]clause.Expression, 0, len(columns))
+			for i, column := range columns {
+				eqs = append(eqs, clause.Eq{Column: unqualifiedColumn(column), Value: values[i]})
+			}
+			tuples = append(tuples, clause.And(eqs...))
+		}
+		if len(tuples) == 1 {
+			return tuples[0]
+		}
+		return clause.Or(tuples...)
+	case clause.AndConditions:
+		exprs := make([]clause.Expression, 0, len(expr.Exprs))
+		for _, e := range expr.Exprs {
+			exprs = append(exprs, toPartiQLCondition(e, softDeleteColumn))
+		}
+		return clause.AndConditions{Exprs: exprs}
+	case clause.OrConditions:
+		exprs := make([]clause.Expression, 0, len(expr.Exprs))
+		for _, e := range expr.Exprs {
+			exprs = append(exprs, toPartiQLCondition(e, softDeleteColumn))
+		}
+		return clause.OrConditions{Exprs: exprs}
+	case clause.NotConditions:
+		exprs := make([]clause.Expression, 0, len(expr.Exprs))
+		for _, e := range expr.Exprs {
+			exprs = append(exprs, toPartiQLCondition(e, softDeleteColumn))
+		}
+		return clause.NotConditions{Exprs: exprs}
+	}
+	return expr
+}
+
+// unqualifiedColumn returns the column without the table name.
+func unqualifiedColumn(column interface{}) interface{} {
+	if c, ok := column.(clause.Column); ok {
+		c.Table = ""
+		return c
+	}
+	return column
+}
+
+// isNilValue reports whether the value is built as NULL.
+func isNilValue(v interface{}) bool {
+	if valuer, ok := v.(driver.Valuer); ok && !isNil(valuer) {
+		v, _ = valuer.Value()
+	}
+	return isNil(v)
+}
+
+// buildLimitClause builds LIMIT clause
+//
+// Since PartiQL for DynamoDB does not support OFFSET, it is not built.
+func buildLimitClause(limit clause.Limit, stmt *gorm.Statement) {
+	if limit.Limit == nil || *limit.Limit <= 0 {
+		return
+	}
+	stmt.WriteString("LIMIT ")
+	stmt.WriteString(strconv.Itoa(*limit.Limit))
+}
@@ -1,6 +1,8 @@
 package dynmgrm
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/google/go-cmp/cmp"
@@ -10,6 +12,7 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
+	"sync"
 	"testing"
 )
 
@@ -52,8 +55,27 @@ func (s mockDialector) Explain(_ string, _ ...interface{}) string {
 func TestValuesClause(t *testing.T) {
 	type test struct {
 		args         clause.Values
+		dialector    gorm.Dialector
+		model        interface{}
 		expectedSQL  string
 		expectedVars []interface{}
+		expectedErr  error
+	}
+	type taggedModel struct {
+		PK      string  `gorm:"primaryKey"`
+		Always  string  `dynmgrm:"zero-value:always"`
+		Null    *string `dynmgrm:"zero-value:null"`
+		Omit    int     `dynmgrm:"zero-value:omitempty"`
+		GSIKey  string  `dynmgrm:"gsi-pk:gsi_key-index"`
+		Default bool
+	}
+	type invalidTaggedModel struct {
+		PK      string `gorm:"primaryKey"`
+		Unknown string `dynmgrm:"zero-value:omit"`
+	}
+	nilString := (*string)(nil)
+	zeroValueColumns := []clause.Column{
+		{Name: "pk"}, {Name: "column1"}, {Name: "column2"}, {Name: "column3"}, {Name: "column4"},
 	}
 	tests := map[string]test{
 		"happy-path/single-column": {
@@ -145,9 +167,76 @@ func TestValuesClause(t *testing.T) {
 					{0, "value1", "", nil},
 				},
 			},
+			expectedSQL:  "VALUE {'pk' : ?, 'column1' : ?, 'column2' : ?, 'column3' : ?}",
+			expectedVars: []interface{}{0, "value1", "", nil},
+		},
+		"happy-path/with-zero-value-policy-always": {
+			args: clause.Values{
+				Columns: zeroValueColumns,
+				Values:  [][]interface{}{{0, "value1", "", false, nilString}},
+			},
+			dialector:    &Dialector{zeroValuePolicy: ZeroValuePolicyAlways},
+			expectedSQL:  "VALUE {'pk' : ?, 'column1' : ?, 'column2' : ?, 'column3' : ?, 'column4' : ?}",
+			expectedVars: []interface{}{0, "value1", "", false, nilString},
+		},
+		"happy-path/with-zero-value-policy-null": {
+			args: clause.Values{
+				Columns: zeroValueColumns,
+				Values:  [][]interface{}{{0, "value1", "", false, nilString}},
+			},
+			dialector:    &Dialector{zeroValuePolicy: ZeroValuePolicyNull},
+			expectedSQL:  "VALUE {'pk' : ?, 'column1' : ?, 'column4' : ?}",
+			expectedVars: []interface{}{0, "value1", nilString},
+		},
+		"happy-path/with-zero-value-policy-omitempty": {
+			args: clause.Values{
+				Columns: zeroValueColumns,
+				Values:  [][]interface{}{{0, "value1", "", false, nilString}},
+			},
+			dialector:    &Dialector{zeroValuePolicy: ZeroValuePolicyOmitEmpty},
 			expectedSQL:  "VALUE {'pk' : ?, 'column1' : ?}",
 			expectedVars: []interface{}{0, "value1"},
 		},
+		"happy-path/with-zero-value-tag": {
+			args: clause.Values{
+				Columns: []clause.Column{
+					{Name: "pk"}, {Name: "always"}, {Name: "null"}, {Name: "omit"}, {Name: "gsi_key"}, {Name: "default"},
+				},
+				Values: [][]interface{}{{"value1", "", nilString, 0, "", false}},
+			},
+			dialector:    &Dialector{zeroValuePolicy: ZeroValuePolicyAlways},
+			model:        &taggedModel{},
+			expectedSQL:  "VALUE {'pk' : ?, 'always' : ?, 'null' : ?, 'default' : ?}",
+			expectedVars: []interface{}{"value1", "", nilString, false},
+		},
+		"happy-path/primary-key-not-first": {
+			args: clause.Values{
+				Columns: []clause.Column{{Name: "column1"}, {Name: "pk"}},
+				Values:  [][]interface{}{{"", "value1"}},
+			},
+			dialector:    &Dialector{zeroValuePolicy: ZeroValuePolicyOmitEmpty},
+			expectedSQL:  "VALUE {'pk' : ?}",
+			expectedVars: []interface{}{"value1"},
+		},
+		"happy-path/empty-set": {
+			args: clause.Values{
+				Columns: []clause.Column{{Name: "pk"}, {Name: "column1"}, {Name: "column2"}},
+				Values:  [][]interface{}{{"value1", sqldav.Set[string]{}, sqldav.List{}}},
+			},
+			dialector:    &Dialector{zeroValuePolicy: ZeroValuePolicyAlways},
+			expectedSQL:  "VALUE {'pk' : ?, 'column2' : ?}",
+			expectedVars: []interface{}{"value1", sqldav.List{}},
+		},
+		"unhappy-path/unknown-zero-value-tag": {
+			args: clause.Values{
+				Columns: []clause.Column{{Name: "pk"}, {Name: "unknown"}},
+				Values:  [][]interface{}{{"value1", ""}},
+			},
+			model:        &invalidTaggedModel{},
+			expectedSQL:  "VALUE {'pk' : ?",
+			expectedVars: []interface{}{"value1"},
+			expectedErr:  ErrInvalidZeroValuePolicy,
+		},
 		"unhappy-path/empty-columns": {
 			args: clause.Values{
 				Columns: []clause.Column{},
@@ -166,18 +255,33 @@ func TestValuesClause(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
+			dialector := tt.dialector
+			if dialector == nil {
+				dialector = &mockDialector{}
+			}
+			sch := &schema.Schema{
+				PrimaryFieldDBNames: []string{"pk"},
+			}
+			if tt.model != nil {
+				var err error
+				sch, err = schema.Parse(tt.model, &sync.Map{}, schema.NamingStrategy{})
+				if err != nil {
+					t.Fatalf("failed to parse schema: %v", err)
+				}
+			}
 			sut := &gorm.Statement{
 				DB: &gorm.DB{
 					Config: &gorm.Config{
-						Dialector: &mockDialector{},
+						Dialector: dialector,
 					},
 				},
-				Schema: &schema.Schema{
-					PrimaryFieldDBNames: []string{"pk"},
-				},
+				Schema: sch,
 			}
 			buildValuesClause(tt.args, sut)
 
+			if !errors.Is(sut.Error, tt.expectedErr) {
+				t.Errorf("error = %v, want %v", sut.Error, tt.expectedErr)
+			}
 			acutalSQL := sut.SQL.String()
 			if diff := cmp.Diff(tt.expectedSQL, acutalSQL); diff != "" {
 				t.Errorf("SQL mismatch (-want +got):\n%s", diff)
@@ -257,9 +361,11 @@ func Test_toClauseBuilder(t *testing.T) {
 func TestBuildSetClause(t *testing.T) {
 	type test struct {
 		set          clause.Set
+		dialector    gorm.Dialector
 		expectedSQL  string
 		expectedVars []interface{}
 	}
+	nilString := (*string)(nil)
 	tests := map[string]test{
 		"happy-path/single-assignment": {
 			set: clause.Set{
@@ -314,9 +420,81 @@ func TestBuildSetClause(t *testing.T) {
 			set: clause.Set{
 				{Column: clause.Column{Name: "column1"}, Value: ListAppend("value1")},
 			},
-			expectedSQL:  `SET "column1"=list_append(column1, ?)`,
+			expectedSQL:  `SET "column1"=list_append("column1", ?)`,
+			expectedVars: []interface{}{sqldav.List{"value1"}},
+		},
+		"happy-path/with_list_prepend": {
+			set: clause.Set{
+				{Column: clause.Column{Name: "column1"}, Value: ListPrepend("value1")},
+			},
+			expectedSQL:  `SET "column1"=list_append(?, "column1")`,
 			expectedVars: []interface{}{sqldav.List{"value1"}},
 		},
+		"happy-path/with_set_add_and_set_delete": {
+			set: clause.Set{
+				{Column: clause.Column{Name: "column1"}, Value: SetAdd("value1")},
+				{Column: clause.Column{Name: "column2"}, Value: SetDelete(1)},
+			},
+			expectedSQL:  `SET "column1"=set_add("column1", ?) SET "column2"=set_delete("column2", ?)`,
+			expectedVars: []interface{}{sqldav.Set[string]{"value1"}, sqldav.Set[int]{1}},
+		},
+		"happy-path/with_increment_and_decrement": {
+			set: clause.Set{
+				{Column: clause.Column{Name: "column1"}, Value: Increment(1)},
+				{Column: clause.Column{Name: "column2"}, Value: Decrement(2)},
+			},
+			expectedSQL:  `SET "column1"="column1" + ? SET "column2"="column2" - ?`,
+			expectedVars: []interface{}{1, 2},
+		},
+		"happy-path/with_nested_path": {
+			set: clause.Set{
+				{Column: clause.Column{Name: "profile.address.city"}, Value: "value1"},
+				{Column: clause.Column{Name: Path("tags", 2)}, Value: "value2"},
+				{Column: clause.Column{Name: Path("profile", "phone-numbers", 0)}, Value: Remove()},
+			},
+			expectedSQL:  `SET "profile"."address"."city"=? SET "tags"[2]=? REMOVE "profile"."phone-numbers"[0]`,
+			expectedVars: []interface{}{"value1", "value2"},
+		},
+		"happy-path/with_remove": {
+			set: clause.Set{
+				{Column: clause.Column{Name: "column1"}, Value: Remove()},
+			},
+			expectedSQL:  `REMOVE "column1"`,
+			expectedVars: nil,
+		},
+		"happy-path/with_set_and_remove": {
+			set: clause.Set{
+				{Column: clause.Column{Name: "column1"}, Value: Remove()},
+				{Column: clause.Column{Name: "column2"}, Value: "value2"},
+				{Column: clause.Column{Name: "column3"}, Value: Remove()},
+			},
+			expectedSQL:  `SET "column2"=? REMOVE "column1" REMOVE "column3"`,
+			expectedVars: []interface{}{"value2"},
+		},
+		"happy-path/primary-key-first": {
+			set: clause.Set{
+				{Column: clause.Column{Name: "pk"}, Value: "value1"},
+				{Column: clause.Column{Name: "column1"}, Value: "value2"},
+			},
+			expectedSQL:  `SET "column1"=?`,
+			expectedVars: []interface{}{"value2"},
+		},
+		"happy-path/nil-pointer-without-remove-nil": {
+			set: clause.Set{
+				{Column: clause.Column{Name: "column1"}, Value: nilString},
+			},
+			expectedSQL:  `SET "column1"=?`,
+			expectedVars: []interface{}{nilString},
+		},
+		"happy-path/nil-pointer-with-remove-nil": {
+			set: clause.Set{
+				{Column: clause.Column{Name: "column1"}, Value: nilString},
+				{Column: clause.Column{Name: "column2"}, Value: "value2"},
+			},
+			dialector:    &Dialector{removeNil: true},
+			expectedSQL:  `SET "column2"=? REMOVE "column1"`,
+			expectedVars: []interface{}{"value2"},
+		},
 	}
 	opts := []cmp.Option{
 		cmp.AllowUnexported(types.AttributeValueMemberS{}),
@@ -327,10 +505,14 @@ func TestBuildSetClause(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
+			dialector := tt.dialector
+			if dialector == nil {
+				dialector = &mockDialector{}
+			}
 			sut := &gorm.Statement{
 				DB: &gorm.DB{
 					Config: &gorm.Config{
-						Dialector: &mockDialector{},
+						Dialector: dialector,
 					},
 				},
 				Schema: &schema.Schema{
@@ -350,3 +532,141 @@ func TestBuildSetClause(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildWhereClause(t *testing.T) {
+	type test struct {
+		where        clause.Where
+		expectedSQL  string
+		expectedVars []interface{}
+	}
+	type softDeletableModel struct {
+		PK        string `gorm:"primaryKey"`
+		SK        int    `gorm:"primaryKey"`
+		DeletedAt gorm.DeletedAt
+	}
+	primaryKeys := []clause.Column{
+		{Table: clause.CurrentTable, Name: "pk"},
+		{Table: clause.CurrentTable, Name: "sk"},
+	}
+	tests := map[string]test{
+		"happy-path/qualified-column": {
+			where: clause.Where{Exprs: []clause.Expression{
+				clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "pk"}, Value: "value1"},
+				clause.Gt{Column: clause.Column{Table: "soft_deletable_models", Name: "sk"}, Value: 1},
+			}},
+			expectedSQL:  `WHERE "pk" = ? AND "sk" > ?`,
+			expectedVars: []interface{}{"value1", 1},
+		},
+		"happy-path/in-with-a-tuple": {
+			where: clause.Where{Exprs: []clause.Expression{
+				clause.IN{Column: primaryKeys, Values: []interface{}{[]interface{}{"value1", 1}}},
+			}},
+			expectedSQL:  `WHERE "pk" = ? AND "sk" = ?`,
+			expectedVars: []interface{}{"value1", 1},
+		},
+		"happy-path/in-with-tuples": {
+			where: clause.Where{Exprs: []clause.Expression{
+				clause.IN{Column: primaryKeys, Values: []interface{}{
+					[]interface{}{"value1", 1},
+					[]interface{}{"value2", 2},
+				}},
+			}},
+			expectedSQL:  `WHERE (("pk" = ? AND "sk" = ?) OR ("pk" = ? AND "sk" = ?))`,
+			expectedVars: []interface{}{"value1", 1, "value2", 2},
+		},
+		"happy-path/in-with-a-column": {
+			where: clause.Where{Exprs: []clause.Expression{
+				clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: "pk"}, Values: []interface{}{"value1", "value2"}},
+			}},
+			expectedSQL:  `WHERE "pk" IN (?,?)`,
+			expectedVars: []interface{}{"value1", "value2"},
+		},
+		"happy-path/soft-delete": {
+			where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "pk = ?", Vars: []interface{}{"value1"}},
+				clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "deleted_at"}, Value: sql.NullString{}},
+			}},
+			expectedSQL:  `WHERE pk = ? AND ("deleted_at" IS MISSING OR "deleted_at" IS NULL)`,
+			expectedVars: []interface{}{"value1"},
+		},
+		"happy-path/null-of-other-column": {
+			where: clause.Where{Exprs: []clause.Expression{
+				clause.Eq{Column: clause.Column{Name: "column1"}, Value: nil},
+			}},
+			expectedSQL: `WHERE "column1" IS NULL`,
+		},
+		"happy-path/nested-conditions": {
+			where: clause.Where{Exprs: []clause.Expression{
+				clause.Not(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "pk"}, Value: "value1"}),
+				clause.Or(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: "sk"}, Value: 1}),
+			}},
+			expectedSQL:  `WHERE "pk" <> ? OR "sk" <> ?`,
+			expectedVars: []interface{}{"value1", 1},
+		},
+		"unhappy-path/empty-where": {
+			where:       clause.Where{},
+			expectedSQL: "",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sch, err := schema.Parse(&softDeletableModel{}, &sync.Map{}, schema.NamingStrategy{})
+			if err != nil {
+				t.Fatalf("failed to parse schema: %v", err)
+			}
+			sut := &gorm.Statement{
+				DB: &gorm.DB{
+					Config: &gorm.Config{
+						Dialector: &mockDialector{},
+					},
+				},
+				Schema: sch,
+				Table:  sch.Table,
+			}
+			buildWhereClause(tt.where, sut)
+
+			if diff := cmp.Diff(tt.expectedSQL, sut.SQL.String()); diff != "" {
+				t.Errorf("SQL mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expectedVars, sut.Vars); diff != "" {
+				t.Errorf("Vars mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildLimitClause(t *testing.T) {
+	type test struct {
+		limit       clause.Limit
+		expectedSQL string
+	}
+	limit := 10
+	zero := 0
+	tests := map[string]test{
+		"happy-path": {
+			limit:       clause.Limit{Limit: &limit},
+			expectedSQL: "LIMIT 10",
+		},
+		"happy-path/offset-is-not-built": {
+			limit:       clause.Limit{Limit: &limit, Offset: 5},
+			expectedSQL: "LIMIT 10",
+		},
+		"unhappy-path/without-limit": {
+			limit:       clause.Limit{Offset: 5},
+			expectedSQL: "",
+		},
+		"unhappy-path/zero": {
+			limit:       clause.Limit{Limit: &zero},
+			expectedSQL: "",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sut := &gorm.Statement{}
+			buildLimitClause(tt.limit, sut)
+			if diff := cmp.Diff(tt.expectedSQL, sut.SQL.String()); diff != "" {
+				t.Errorf("SQL mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
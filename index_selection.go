@@ -0,0 +1,218 @@
+package dynmgrm
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"slices"
+	"strings"
+)
+
+// compatibility check
+var _ clause.Interface = (*noIndexSelectionExpression)(nil)
+
+// ErrAmbiguousSecondaryIndex occurs when the conditions of the query match the keys of multiple secondary indexes.
+var ErrAmbiguousSecondaryIndex = errors.New("the conditions match the keys of multiple secondary indexes")
+
+// noIndexSelectionExpression is a clause.Interface that makes the query use the table instead of the secondary index
+// selected automatically. It is never built.
+type noIndexSelectionExpression struct{}
+
+// Name returns the name of the clause.
+func (n noIndexSelectionExpression) Name() string {
+	return "NO INDEX SELECTION"
+}
+
+// Build does nothing, since the clause is not a part of the statement.
+func (n noIndexSelectionExpression) Build(clause.Builder) {}
+
+// MergeClause merges the noIndexSelectionExpression into the clause.
+func (n noIndexSelectionExpression) MergeClause(clause *clause.Clause) {
+	clause.Expression = n
+}
+
+// NoIndexSelection makes the query use the table, even if its conditions match the keys of a secondary index
+// and WithSecondaryIndexSelection is enabled.
+//
+// e.g. db.Clauses(dynmgrm.NoIndexSelection()).Where(`kind = ?`, "Login").Find(&events)
+func NoIndexSelection() noIndexSelectionExpression {
+	return noIndexSelectionExpression{}
+}
+
+// WithSecondaryIndexSelection sets whether the queries of the models use the secondary index
+// whose keys the WHERE clause pins, instead of the table. It can be opted out for each query with NoIndexSelection.
+//
+// The GSIs are read eventually consistently, so the items written just before may not be found,
+// and they are not selected for the queries with ConsistentRead.
+//
+// Default: false
+func WithSecondaryIndexSelection(enabled bool) func(*config) {
+	return func(config *config) {
+		config.indexSelection = enabled
+	}
+}
+
+// secondaryIndexKeys is the key schema of the secondary index, which is read from the tags of the model.
+type secondaryIndexKeys struct {
+	pk   string
+	sk   string
+	kind secondaryIndexKind
+	// projectsAll is whether all the attributes of the model are projected into the index
+	projectsAll bool
+}
+
+// secondaryIndexesOf returns the key schemas of the secondary indexes of the model, by the names of the indexes.
+func secondaryIndexesOf(sch *schema.Schema, tablePK string) map[string]*secondaryIndexKeys {
+	indexes := make(map[string]*secondaryIndexKeys)
+	indexOf := func(name string) *secondaryIndexKeys {
+		if _, ok := indexes[name]; !ok {
+			indexes[name] = &secondaryIndexKeys{projectsAll: true}
+		}
+		return indexes[name]
+	}
+	for _, field := range sch.Fields {
+		tag := newDynmgrmTag(field.Tag)
+		for _, property := range tag.IndexProperty {
+			index := indexOf(property.Name)
+			index.kind = property.Kind
+			switch {
+			case property.PK:
+				index.pk = field.DBName
+			case property.SK:
+				index.sk = field.DBName
+				if property.Kind == secondaryIndexKindLSI {
+					index.pk = tablePK
+				}
+			}
+		}
+		for _, name := range tag.NonProjective {
+			indexOf(name).projectsAll = false
+		}
+	}
+	for name, index := range indexes {
+		if index.pk == "" {
+			// only non-projective is tagged
+			delete(indexes, name)
+		}
+	}
+	return indexes
+}
+
+// resolveSecondaryIndex is the query callback that resolves the table or the secondary index to be queried,
+// before the query is built.
+//
+// The secondary index of the model is applied or selected automatically,
+// and the query is guarded by the ScanPolicy with the resolved keys.
+func resolveSecondaryIndex(db *gorm.DB) {
+	if db.Error != nil || db.Statement.SQL.Len() != 0 {
+		return
+	}
+	if applyModelSecondaryIndex(db.Statement) && selectSecondaryIndex(db.Statement) {
+		guardScan(db.Statement)
+	}
+}
+
+// selectSecondaryIndex makes the query use the secondary index whose keys the WHERE clause pins, instead of the table.
+//
+// The indexes are selected when the partition key of the table is not pinned, but the one of the index is.
+// The ones sharing the partition key with the table, such as LSIs, are selected
+// when the sort key of the table is not pinned, but the one of the index is.
+// The indexes that cannot sort the items by ORDER BY, or that cannot be read consistently with ConsistentRead,
+// are not selected.
+// If the conditions match multiple indexes, the ones whose sort keys are also pinned are preferred,
+// and ErrAmbiguousSecondaryIndex is added if they are still multiple.
+// It reports false if the error is added.
+//
+// It is enabled by WithSecondaryIndexSelection.
+func selectSecondaryIndex(stmt *gorm.Statement) bool {
+	if dialector, ok := stmt.DB.Dialector.(*Dialector); !ok || !dialector.indexSelection {
+		return true
+	}
+	if stmt.Schema == nil || strings.Contains(stmt.Table, ".") {
+		// the secondary index has been specified
+		return true
+	}
+	if _, ok := stmt.Clauses[NoIndexSelection().Name()]; ok {
+		return true
+	}
+	tablePK, tableSK, ok := keysOf(stmt)
+	if !ok {
+		return true
+	}
+	tablePinned := pinsKey(stmt, tablePK)
+	if tablePinned && (tableSK == "" || pinsKey(stmt, tableSK)) {
+		return true
+	}
+	_, consistentRead := stmt.Clauses[ConsistentRead().Name()]
+
+	var candidates []string
+	indexes := secondaryIndexesOf(stmt.Schema, tablePK)
+	for name, index := range indexes {
+		if !index.projectsAll {
+			continue
+		}
+		if !pinsKey(stmt, index.pk) {
+			continue
+		}
+		if index.pk == tablePK {
+			// e.g. LSIs, which are worth it only if their sort keys are pinned
+			if index.sk == "" || !pinsKey(stmt, index.sk) {
+				continue
+			}
+		} else if tablePinned {
+			continue
+		}
+		if index.kind == secondaryIndexKindGSI && consistentRead {
+			// GSIs do not support strongly consistent reads
+			continue
+		}
+		if !ordersByKeys(stmt, index.pk, index.sk) {
+			continue
+		}
+		candidates = append(candidates, name)
+	}
+	if len(candidates) > 1 {
+		withSK := slices.DeleteFunc(slices.Clone(candidates), func(name string) bool {
+			return indexes[name].sk == "" || !pinsKey(stmt, indexes[name].sk)
+		})
+		if len(withSK) > 0 {
+			candidates = withSK
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return true
+	case 1:
+		SecondaryIndex(candidates[0]).ModifyStatement(stmt)
+		return true
+	}
+	slices.Sort(candidates)
+	stmt.AddError(fmt.Errorf("%w: %s", ErrAmbiguousSecondaryIndex, strings.Join(candidates, ", ")))
+	return false
+}
+
+// ordersByKeys reports whether ORDER BY of the statement has only the keys,
+// except for the primary key that First/Last add implicitly, which is resolved after the index is selected.
+func ordersByKeys(stmt *gorm.Statement, pk, sk string) bool {
+	c, ok := stmt.Clauses["ORDER BY"]
+	if !ok {
+		return true
+	}
+	orderBy, ok := c.Expression.(clause.OrderBy)
+	if !ok {
+		return true
+	}
+	for _, column := range orderBy.Columns {
+		if column.Column.Name == clause.PrimaryKey {
+			continue
+		}
+		for _, name := range orderByColumnNames(column) {
+			if name != pk && name != sk {
+				return false
+			}
+		}
+	}
+	return true
+}
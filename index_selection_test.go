@@ -0,0 +1,218 @@
+package dynmgrm
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"gorm.io/gorm"
+	"testing"
+)
+
+type indexedEvent struct {
+	UserID    string `dynmgrm:"pk;gsi-pk:user_id-score-index"`
+	EventedAt string `dynmgrm:"sk"`
+	Kind      string `dynmgrm:"gsi-pk:kind-index;gsi-pk:kind-name-index"`
+	Name      string `dynmgrm:"gsi-sk:kind-name-index"`
+	Status    string `dynmgrm:"lsi-sk:status-index"`
+	Source    string `dynmgrm:"gsi-pk:source-index"`
+	Region    string `dynmgrm:"gsi-pk:region-index"`
+	Note      string `dynmgrm:"non-projective:[region-index]"`
+	Score     int    `dynmgrm:"gsi-sk:user_id-score-index"`
+}
+
+func TestIndexSelection(t *testing.T) {
+	type want struct {
+		sql string
+		err error
+	}
+	type test struct {
+		operation func(db *gorm.DB) *gorm.DB
+		disabled  bool
+		want      want
+	}
+	tests := map[string]test{
+		"happy_path/gsi": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`source = ?`, "web").Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events"."source-index" WHERE source = ?`,
+			},
+		},
+		"happy_path/gsi_of_model": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(&indexedEvent{Source: "web"}).Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events"."source-index" WHERE "source" = ?`,
+			},
+		},
+		"happy_path/gsi_with_sort_key": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`kind = ? AND name = ?`, "Login", "Alice").Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events"."kind-name-index" WHERE kind = ? AND name = ?`,
+			},
+		},
+		"happy_path/lsi": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ?`, "User1").Where(`status = ?`, "Active").Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events"."status-index" WHERE user_id = ? AND status = ?`,
+			},
+		},
+		"happy_path/gsi_sharing_partition_key_with_table": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ? AND score = ?`, "User1", 100).Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events"."user_id-score-index" WHERE user_id = ? AND score = ?`,
+			},
+		},
+		"happy_path/table_partition_key": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ? AND source = ?`, "User1", "web").Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events" WHERE user_id = ? AND source = ?`,
+			},
+		},
+		"happy_path/table_primary_key": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ? AND evented_at = ? AND status = ?`, "User1", "2024", "Active").Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events" WHERE user_id = ? AND evented_at = ? AND status = ?`,
+			},
+		},
+		"happy_path/secondary_index_specified": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Table("indexed_events").
+					Clauses(SecondaryIndex("kind-index")).
+					Where(`kind = ?`, "Login").
+					Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events"."kind-index" WHERE kind = ?`,
+			},
+		},
+		"happy_path/no_index_selection": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(NoIndexSelection()).Where(`source = ?`, "web").Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events" WHERE source = ?`,
+			},
+		},
+		"happy_path/disabled": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`source = ?`, "web").Find(&[]indexedEvent{})
+			},
+			disabled: true,
+			want: want{
+				sql: `SELECT * FROM "indexed_events" WHERE source = ?`,
+			},
+		},
+		"happy_path/order_by_sort_key_of_table": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ? AND status = ?`, "User1", "Active").Order("evented_at").Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events" WHERE user_id = ? AND status = ? ORDER BY evented_at`,
+			},
+		},
+		"happy_path/order_by_sort_key_of_index": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`kind = ?`, "Login").Order("name DESC").Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events"."kind-name-index" WHERE kind = ? ORDER BY name DESC`,
+			},
+		},
+		"happy_path/consistent_read": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(ConsistentRead()).Where(`source = ?`, "web").Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events" WHERE source = ? WITH ConsistentRead=true`,
+			},
+		},
+		"happy_path/non_projective": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`region = ?`, "ap-northeast-1").Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events" WHERE region = ?`,
+			},
+		},
+		"happy_path/partition_key_in_or": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`source = ?`, "web").Or(`name = ?`, "Alice").Find(&[]indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events" WHERE source = ? OR name = ?`,
+			},
+		},
+		"happy_path/last_on_gsi": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`source = ?`, "web").Last(&indexedEvent{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events"."source-index" WHERE source = ? LIMIT 1`,
+			},
+		},
+		"happy_path/row": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				tx := db.Model(&indexedEvent{}).Select("kind").Where(`source = ?`, "web")
+				tx.Row()
+				return tx
+			},
+			want: want{
+				sql: `SELECT "kind" FROM "indexed_events"."source-index" WHERE source = ?`,
+			},
+		},
+		"happy_path/unknown_model": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Table("indexed_events").Where(`source = ?`, "web").Find(&[]map[string]interface{}{})
+			},
+			want: want{
+				sql: `SELECT * FROM "indexed_events" WHERE source = ?`,
+			},
+		},
+		"unhappy_path/ambiguous": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`kind = ?`, "Login").Find(&[]indexedEvent{})
+			},
+			want: want{
+				err: ErrAmbiguousSecondaryIndex,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := openTestDB(t, &fakeConnPool{}, nil, &gorm.Config{SkipDefaultTransaction: true, DryRun: true})
+			db.Dialector.(*Dialector).indexSelection = !tt.disabled
+			result := tt.operation(db)
+			if !errors.Is(result.Error, tt.want.err) {
+				t.Fatalf("error = %v, want %v", result.Error, tt.want.err)
+			}
+			if tt.want.err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.want.sql, result.Statement.SQL.String()); diff != "" {
+				t.Errorf("SQL mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIndexSelection_ambiguous(t *testing.T) {
+	db := openTestDB(t, &fakeConnPool{}, nil, &gorm.Config{SkipDefaultTransaction: true, DryRun: true})
+	db.Dialector.(*Dialector).indexSelection = true
+	err := db.Where(`kind = ?`, "Login").Find(&[]indexedEvent{}).Error
+	want := "the conditions match the keys of multiple secondary indexes: kind-index, kind-name-index"
+	if err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %s", err, want)
+	}
+}
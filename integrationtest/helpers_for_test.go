@@ -70,6 +70,26 @@ func (t TestTableWithNested) TableName() string {
 	return "test_tables"
 }
 
+type TestTablePKSomeStringIndex struct {
+	PK            string `gorm:"primaryKey" dynmgrm:"gsi-pk:pk-some_string-index"`
+	SK            int    `gorm:"primaryKey"`
+	SomeString    string `dynmgrm:"gsi-sk:pk-some_string-index"`
+	SomeInt       int
+	SomeFloat     float64
+	SomeBool      bool
+	SomeBinary    []byte
+	SomeList      sqldav.List
+	SomeMap       sqldav.Map
+	SomeStringSet sqldav.Set[string]
+	SomeIntSet    sqldav.Set[int]
+	SomeFloatSet  sqldav.Set[float64]
+	SomeBinarySet sqldav.Set[[]byte]
+}
+
+func (t TestTablePKSomeStringIndex) TableName() string {
+	return "test_tables"
+}
+
 type NestedAttribute struct {
 	SomeString string
 	SomeNumber float64
@@ -47,6 +47,12 @@ func Test_Insert_With_Create(t *testing.T) {
 			"some_int_set":    &dynamodb.AttributeValue{NS: aws.StringSlice([]string{"1", "2", "3"})},
 			"some_float_set":  &dynamodb.AttributeValue{NS: aws.StringSlice([]string{"1.1", "2.2", "3.3"})},
 			"some_binary_set": &dynamodb.AttributeValue{BS: [][]byte{[]byte("a"), []byte("b"), []byte("c")}},
+			"some_string":     &dynamodb.AttributeValue{S: aws.String("")},
+			"some_int":        &dynamodb.AttributeValue{N: aws.String("0")},
+			"some_float":      &dynamodb.AttributeValue{N: aws.String("0")},
+			"some_bool":       &dynamodb.AttributeValue{BOOL: aws.Bool(false)},
+			"some_binary":     &dynamodb.AttributeValue{NULL: aws.Bool(true)},
+			"any":             &dynamodb.AttributeValue{S: aws.String("")},
 		},
 	}
 	actual := scanData(t, testTableName)
@@ -93,6 +99,12 @@ func Test_Insert_With_Tx_Commit(t *testing.T) {
 			"some_int_set":    &dynamodb.AttributeValue{NS: aws.StringSlice([]string{"1", "2", "3"})},
 			"some_float_set":  &dynamodb.AttributeValue{NS: aws.StringSlice([]string{"1.1", "2.2", "3.3"})},
 			"some_binary_set": &dynamodb.AttributeValue{BS: [][]byte{[]byte("a"), []byte("b"), []byte("c")}},
+			"some_string":     &dynamodb.AttributeValue{S: aws.String("")},
+			"some_int":        &dynamodb.AttributeValue{N: aws.String("0")},
+			"some_float":      &dynamodb.AttributeValue{N: aws.String("0")},
+			"some_bool":       &dynamodb.AttributeValue{BOOL: aws.Bool(false)},
+			"some_binary":     &dynamodb.AttributeValue{NULL: aws.Bool(true)},
+			"any":             &dynamodb.AttributeValue{S: aws.String("")},
 		},
 	}
 	actual := scanData(t, testTableName)
@@ -169,6 +181,12 @@ func Test_Insert_With_Transaction_Success(t *testing.T) {
 			"some_int_set":    &dynamodb.AttributeValue{NS: aws.StringSlice([]string{"1", "2", "3"})},
 			"some_float_set":  &dynamodb.AttributeValue{NS: aws.StringSlice([]string{"1.1", "2.2", "3.3"})},
 			"some_binary_set": &dynamodb.AttributeValue{BS: [][]byte{[]byte("a"), []byte("b"), []byte("c")}},
+			"some_string":     &dynamodb.AttributeValue{S: aws.String("")},
+			"some_int":        &dynamodb.AttributeValue{N: aws.String("0")},
+			"some_float":      &dynamodb.AttributeValue{N: aws.String("0")},
+			"some_bool":       &dynamodb.AttributeValue{BOOL: aws.Bool(false)},
+			"some_binary":     &dynamodb.AttributeValue{NULL: aws.Bool(true)},
+			"any":             &dynamodb.AttributeValue{S: aws.String("")},
 		},
 	}
 	actual := scanData(t, testTableName)
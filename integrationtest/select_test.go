@@ -403,48 +403,90 @@ func Test_Select_With_Secondary_Index(t *testing.T) {
 		t.Errorf("withTableNameDotIndexName mismatch (-want +got):\n%s", diff)
 	}
 
-	// Not supported yet
-	//withModelExpect := TestTablePKSomeStringIndex{
-	//	PK:         "Partition1",
-	//	SomeString: "こんにちは",
-	//	SK:         2,
-	//	SomeInt:    2,
-	//	SomeFloat:  2.2,
-	//	SomeBool:   false,
-	//	SomeBinary: []byte("GHI"),
-	//	SomeList: sqldav.List{
-	//		"こんにちは",
-	//		float64(2),
-	//		2.2,
-	//		false,
-	//		[]byte("GHI"),
-	//	},
-	//	SomeMap: sqldav.Map{
-	//		"some_string": "こんにちは",
-	//		"some_number": 2.2,
-	//		"some_bool":   false,
-	//		"some_binary": []byte("GHI"),
-	//	},
-	//	SomeStringSet: sqldav.Set[string]{"こんにちは", "世界"},
-	//	SomeIntSet:    sqldav.Set[int]{2, 4},
-	//	SomeFloatSet:  sqldav.Set[float64]{2.2, 4.4},
-	//	SomeBinarySet: sqldav.Set[[]byte]{[]byte("GHI"), []byte("JKL")},
-	//}
-	//var withModel = TestTablePKSomeStringIndex{
-	//	PK:         "Partition1",
-	//	SomeString: "こんにちは",
-	//}
-	//err = db.Clauses(
-	//	dynmgrm.SecondaryIndex(
-	//		"test_tables.pk-some_string-index"),
-	//).First(&withModel).Error
-	//if err != nil {
-	//	t.Errorf("unexpected error: %v", err)
-	//	err = nil
-	//}
-	//if diff := cmp.Diff(withModelExpect, withModel, setCmpOpts...); diff != "" {
-	//	t.Errorf("withModelAs mismatch (-want +got):\n%s", diff)
-	//}
+	withModelExpect := []TestTablePKSomeStringIndex{
+		{
+			PK:         "Partition1",
+			SK:         2,
+			SomeString: "こんにちは",
+			SomeInt:    2,
+			SomeFloat:  2.2,
+			SomeBool:   false,
+			SomeBinary: []byte("GHI"),
+			SomeList: sqldav.List{
+				"こんにちは",
+				float64(2),
+				2.2,
+				false,
+				[]byte("GHI"),
+			},
+			SomeMap: sqldav.Map{
+				"some_string": "こんにちは",
+				"some_number": 2.2,
+				"some_bool":   false,
+				"some_binary": []byte("GHI"),
+			},
+			SomeStringSet: sqldav.Set[string]{"こんにちは", "世界"},
+			SomeIntSet:    sqldav.Set[int]{2, 4},
+			SomeFloatSet:  sqldav.Set[float64]{2.2, 4.4},
+			SomeBinarySet: sqldav.Set[[]byte]{[]byte("GHI"), []byte("JKL")},
+		},
+	}
+	var withModel []TestTablePKSomeStringIndex
+	err = db.Model(&TestTablePKSomeStringIndex{}).Clauses(
+		dynmgrm.SecondaryIndex("pk-some_string-index"),
+	).Where(`pk = ? AND some_string = ?`, "Partition1", "こんにちは").Find(&withModel).Error
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		err = nil
+	}
+	if diff := cmp.Diff(withModelExpect, withModel, setCmpOpts...); diff != "" {
+		t.Errorf("withModel mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_Select_With_Secondary_Index_Selection(t *testing.T) {
+	db := getGormDB(t)
+	dataPreparation(t, testData, testTableName)
+	defer dataCleanup(t, testData, testTableName)
+
+	expected := []TestTablePKSomeStringIndex{
+		{
+			PK:         "Partition1",
+			SK:         2,
+			SomeString: "こんにちは",
+			SomeInt:    2,
+			SomeFloat:  2.2,
+			SomeBool:   false,
+			SomeBinary: []byte("GHI"),
+			SomeList: sqldav.List{
+				"こんにちは",
+				float64(2),
+				2.2,
+				false,
+				[]byte("GHI"),
+			},
+			SomeMap: sqldav.Map{
+				"some_string": "こんにちは",
+				"some_number": 2.2,
+				"some_bool":   false,
+				"some_binary": []byte("GHI"),
+			},
+			SomeStringSet: sqldav.Set[string]{"こんにちは", "世界"},
+			SomeIntSet:    sqldav.Set[int]{2, 4},
+			SomeFloatSet:  sqldav.Set[float64]{2.2, 4.4},
+			SomeBinarySet: sqldav.Set[[]byte]{[]byte("GHI"), []byte("JKL")},
+		},
+	}
+
+	var result []TestTablePKSomeStringIndex
+	err := db.Where(`pk = ? AND some_string = ?`, "Partition1", "こんにちは").Find(&result).Error
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		err = nil
+	}
+	if diff := cmp.Diff(expected, result, setCmpOpts...); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
 }
 
 func Test_Select_With_BeginWith(t *testing.T) {
@@ -1292,6 +1334,125 @@ func Test_Select_With_Or(t *testing.T) {
 	}
 }
 
+func Test_Select_With_BeginsWith_helper(t *testing.T) {
+	db := getGormDB(t)
+	dataPreparation(t, testData, testTableName)
+	defer dataCleanup(t, testData, testTableName)
+
+	expected := []TestTable{
+		{
+			PK:         "Partition1",
+			SK:         1,
+			SomeString: "Hello",
+			SomeInt:    1,
+			SomeFloat:  1.1,
+			SomeBool:   true,
+			SomeBinary: []byte("ABC"),
+			SomeList: sqldav.List{
+				"Hello",
+				float64(1),
+				1.1,
+				true,
+				[]byte("ABC"),
+			},
+			SomeMap: sqldav.Map{
+				"some_string": "Hello",
+				"some_number": 1.1,
+				"some_bool":   true,
+				"some_binary": []byte("ABC"),
+			},
+			SomeStringSet: sqldav.Set[string]{"Hello", "World"},
+			SomeIntSet:    sqldav.Set[int]{1, 2},
+			SomeFloatSet:  sqldav.Set[float64]{1.1, 2.2},
+			SomeBinarySet: sqldav.Set[[]byte]{[]byte("ABC"), []byte("DEF")},
+			Any:           "any",
+		},
+		{
+			PK:         "Partition2",
+			SK:         1,
+			SomeString: "Hello",
+			SomeInt:    1,
+			SomeFloat:  1.1,
+			SomeBool:   true,
+			SomeBinary: []byte("ABC"),
+			SomeList: sqldav.List{
+				"Hello",
+				float64(1),
+				1.1,
+				true,
+				[]byte("ABC"),
+			},
+			SomeMap: sqldav.Map{
+				"some_string": "Hello",
+				"some_number": 1.1,
+				"some_bool":   true,
+				"some_binary": []byte("ABC"),
+			},
+			SomeStringSet: sqldav.Set[string]{"Hello", "World"},
+			SomeIntSet:    sqldav.Set[int]{1, 2},
+			SomeFloatSet:  sqldav.Set[float64]{1.1, 2.2},
+			SomeBinarySet: sqldav.Set[[]byte]{[]byte("ABC"), []byte("DEF")},
+			Any:           "any",
+		},
+		{
+			PK:         "Partition3",
+			SK:         1,
+			SomeString: "Hello",
+			SomeInt:    1,
+			SomeFloat:  1.1,
+			SomeBool:   true,
+			SomeBinary: []byte("ABC"),
+		},
+	}
+
+	var result []TestTable
+	err := db.Table("test_tables").Where(dynmgrm.BeginsWith("some_string", "H")).Scan(&result).Error
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		err = nil
+	}
+	if diff := cmp.Diff(expected, result, setCmpOpts...); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_Select_With_IsMissing_helper(t *testing.T) {
+	db := getGormDB(t)
+	dataPreparation(t, testData, testTableName)
+	defer dataCleanup(t, testData, testTableName)
+
+	expected := []TestTable{
+		{
+			PK:         "Partition3",
+			SK:         1,
+			SomeString: "Hello",
+			SomeInt:    1,
+			SomeFloat:  1.1,
+			SomeBool:   true,
+			SomeBinary: []byte("ABC"),
+		},
+		{
+			PK:         "Partition3",
+			SK:         2,
+			SomeString: "こんにちは",
+			SomeInt:    2,
+			SomeFloat:  2.2,
+			SomeBool:   false,
+			SomeBinary: []byte("GHI"),
+		},
+	}
+
+	var result []TestTable
+	err := db.Table("test_tables").Where(dynmgrm.IsMissing("some_list")).Scan(&result).Error
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		err = nil
+	}
+	if diff := cmp.Diff(expected, result, setCmpOpts...); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func Test_Select_With_TypedList(t *testing.T) {
 	db := getGormDB(t)
 	dataPreparation(t, testDataForTypedList, testTableName)
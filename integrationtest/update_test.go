@@ -1306,6 +1306,285 @@ func Test_Update_With_ListAppend_helper(t *testing.T) {
 	}
 }
 
+func Test_Update_With_SetAdd_helper(t *testing.T) {
+	db := getGormDB(t)
+	dataPreparation(t, testData, testTableName)
+	defer dataCleanup(t, testData, testTableName)
+
+	expect := map[string]*dynamodb.AttributeValue{
+		"pk": {
+			S: aws.String("Partition1"),
+		},
+		"sk": {
+			N: aws.String("1"),
+		},
+		"some_string": {
+			S: aws.String("Hello"),
+		},
+		"some_int": {
+			N: aws.String("1"),
+		},
+		"some_float": {
+			N: aws.String("1.1"),
+		},
+		"some_bool": {
+			BOOL: aws.Bool(true),
+		},
+		"some_binary": {
+			B: []byte("ABC"),
+		},
+		"some_list": {
+			L: []*dynamodb.AttributeValue{
+				{
+					S: aws.String("Hello"),
+				},
+				{
+					N: aws.String("1"),
+				},
+				{
+					N: aws.String("1.1"),
+				},
+				{
+					BOOL: aws.Bool(true),
+				},
+				{
+					B: []byte("ABC"),
+				},
+			},
+		},
+		"some_map": {
+			M: map[string]*dynamodb.AttributeValue{
+				"some_string": {
+					S: aws.String("Hello"),
+				},
+				"some_number": {
+					N: aws.String("1.1"),
+				},
+				"some_bool": {
+					BOOL: aws.Bool(true),
+				},
+				"some_binary": {
+					B: []byte("ABC"),
+				},
+			},
+		},
+		"some_string_set": {
+			SS: []*string{aws.String("Hello"), aws.String("World"), aws.String("Bye")},
+		},
+		"some_int_set": {
+			NS: []*string{aws.String("1"), aws.String("2")},
+		},
+		"some_float_set": {
+			NS: []*string{aws.String("1.1"), aws.String("2.2")},
+		},
+		"some_binary_set": {
+			BS: [][]byte{[]byte("ABC"), []byte("DEF")},
+		},
+		"any": {
+			S: aws.String("any"),
+		},
+	}
+
+	db.Model(
+		&TestTable{
+			PK: "Partition1",
+			SK: 1,
+		}).Update("some_string_set", dynmgrm.SetAdd("Bye"))
+
+	result := getData(t, testTableName, "Partition1", 1)
+
+	if diff := cmp.Diff(expect, result, append(avCmpOpts, setCmpOpts...)...); diff != "" {
+		t.Errorf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_Update_With_Increment_helper(t *testing.T) {
+	db := getGormDB(t)
+	dataPreparation(t, testData, testTableName)
+	defer dataCleanup(t, testData, testTableName)
+
+	expect := map[string]*dynamodb.AttributeValue{
+		"pk": {
+			S: aws.String("Partition1"),
+		},
+		"sk": {
+			N: aws.String("1"),
+		},
+		"some_string": {
+			S: aws.String("Hello"),
+		},
+		"some_int": {
+			N: aws.String("3"),
+		},
+		"some_float": {
+			N: aws.String("1.1"),
+		},
+		"some_bool": {
+			BOOL: aws.Bool(true),
+		},
+		"some_binary": {
+			B: []byte("ABC"),
+		},
+		"some_list": {
+			L: []*dynamodb.AttributeValue{
+				{
+					S: aws.String("Hello"),
+				},
+				{
+					N: aws.String("1"),
+				},
+				{
+					N: aws.String("1.1"),
+				},
+				{
+					BOOL: aws.Bool(true),
+				},
+				{
+					B: []byte("ABC"),
+				},
+			},
+		},
+		"some_map": {
+			M: map[string]*dynamodb.AttributeValue{
+				"some_string": {
+					S: aws.String("Hello"),
+				},
+				"some_number": {
+					N: aws.String("1.1"),
+				},
+				"some_bool": {
+					BOOL: aws.Bool(true),
+				},
+				"some_binary": {
+					B: []byte("ABC"),
+				},
+			},
+		},
+		"some_string_set": {
+			SS: []*string{aws.String("Hello"), aws.String("World")},
+		},
+		"some_int_set": {
+			NS: []*string{aws.String("1"), aws.String("2")},
+		},
+		"some_float_set": {
+			NS: []*string{aws.String("1.1"), aws.String("2.2")},
+		},
+		"some_binary_set": {
+			BS: [][]byte{[]byte("ABC"), []byte("DEF")},
+		},
+		"any": {
+			S: aws.String("any"),
+		},
+	}
+
+	db.Model(
+		&TestTable{
+			PK: "Partition1",
+			SK: 1,
+		}).Update("some_int", dynmgrm.Increment(2))
+
+	result := getData(t, testTableName, "Partition1", 1)
+
+	if diff := cmp.Diff(expect, result, append(avCmpOpts, setCmpOpts...)...); diff != "" {
+		t.Errorf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_Update_With_Path(t *testing.T) {
+	db := getGormDB(t)
+	dataPreparation(t, testData, testTableName)
+	defer dataCleanup(t, testData, testTableName)
+
+	expect := map[string]*dynamodb.AttributeValue{
+		"pk": {
+			S: aws.String("Partition1"),
+		},
+		"sk": {
+			N: aws.String("1"),
+		},
+		"some_string": {
+			S: aws.String("Hello"),
+		},
+		"some_int": {
+			N: aws.String("1"),
+		},
+		"some_float": {
+			N: aws.String("1.1"),
+		},
+		"some_bool": {
+			BOOL: aws.Bool(true),
+		},
+		"some_binary": {
+			B: []byte("ABC"),
+		},
+		"some_list": {
+			L: []*dynamodb.AttributeValue{
+				{
+					S: aws.String("Bye"),
+				},
+				{
+					N: aws.String("1"),
+				},
+				{
+					N: aws.String("1.1"),
+				},
+				{
+					BOOL: aws.Bool(true),
+				},
+				{
+					B: []byte("ABC"),
+				},
+			},
+		},
+		"some_map": {
+			M: map[string]*dynamodb.AttributeValue{
+				"some_string": {
+					S: aws.String("World"),
+				},
+				"some_number": {
+					N: aws.String("1.1"),
+				},
+				"some_bool": {
+					BOOL: aws.Bool(true),
+				},
+				"some_binary": {
+					B: []byte("ABC"),
+				},
+			},
+		},
+		"some_string_set": {
+			SS: []*string{aws.String("Hello"), aws.String("World")},
+		},
+		"some_int_set": {
+			NS: []*string{aws.String("1"), aws.String("2")},
+		},
+		"some_float_set": {
+			NS: []*string{aws.String("1.1"), aws.String("2.2")},
+		},
+		"some_binary_set": {
+			BS: [][]byte{[]byte("ABC"), []byte("DEF")},
+		},
+		"any": {
+			S: aws.String("any"),
+		},
+	}
+
+	db.Model(
+		&TestTable{
+			PK: "Partition1",
+			SK: 1,
+		}).Updates(map[string]interface{}{
+		"some_map.some_string":       "World",
+		dynmgrm.Path("some_list", 0): "Bye",
+	})
+
+	result := getData(t, testTableName, "Partition1", 1)
+
+	if diff := cmp.Diff(expect, result, append(avCmpOpts, setCmpOpts...)...); diff != "" {
+		t.Errorf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func Test_Update_With_Save_Has_NetedStruct_Column(t *testing.T) {
 	db := getGormDB(t)
 	dataPreparation(t, testDataForNested, testTableName)
@@ -10,9 +10,11 @@
 package mocks
 
 import (
+	context "context"
 	sql "database/sql"
 	reflect "reflect"
 
+	dynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	gomock "go.uber.org/mock/gomock"
 	gorm "gorm.io/gorm"
 	callbacks "gorm.io/gorm/callbacks"
@@ -120,3 +122,107 @@ func (mr *MockCallbacksRegistererMockRecorder) Register(db, config any) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockCallbacksRegisterer)(nil).Register), db, config)
 }
+
+// MockDynamoDBAPI is a mock of DynamoDBAPI interface.
+type MockDynamoDBAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockDynamoDBAPIMockRecorder
+	isgomock struct{}
+}
+
+// MockDynamoDBAPIMockRecorder is the mock recorder for MockDynamoDBAPI.
+type MockDynamoDBAPIMockRecorder struct {
+	mock *MockDynamoDBAPI
+}
+
+// NewMockDynamoDBAPI creates a new mock instance.
+func NewMockDynamoDBAPI(ctrl *gomock.Controller) *MockDynamoDBAPI {
+	mock := &MockDynamoDBAPI{ctrl: ctrl}
+	mock.recorder = &MockDynamoDBAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDynamoDBAPI) EXPECT() *MockDynamoDBAPIMockRecorder {
+	return m.recorder
+}
+
+// BatchExecuteStatement mocks base method.
+func (m *MockDynamoDBAPI) BatchExecuteStatement(ctx context.Context, params *dynamodb.BatchExecuteStatementInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchExecuteStatementOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "BatchExecuteStatement", varargs...)
+	ret0, _ := ret[0].(*dynamodb.BatchExecuteStatementOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchExecuteStatement indicates an expected call of BatchExecuteStatement.
+func (mr *MockDynamoDBAPIMockRecorder) BatchExecuteStatement(ctx, params any, optFns ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchExecuteStatement", reflect.TypeOf((*MockDynamoDBAPI)(nil).BatchExecuteStatement), varargs...)
+}
+
+// ExecuteStatement mocks base method.
+func (m *MockDynamoDBAPI) ExecuteStatement(ctx context.Context, params *dynamodb.ExecuteStatementInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ExecuteStatementOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecuteStatement", varargs...)
+	ret0, _ := ret[0].(*dynamodb.ExecuteStatementOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteStatement indicates an expected call of ExecuteStatement.
+func (mr *MockDynamoDBAPIMockRecorder) ExecuteStatement(ctx, params any, optFns ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteStatement", reflect.TypeOf((*MockDynamoDBAPI)(nil).ExecuteStatement), varargs...)
+}
+
+// ExecuteTransaction mocks base method.
+func (m *MockDynamoDBAPI) ExecuteTransaction(ctx context.Context, params *dynamodb.ExecuteTransactionInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ExecuteTransactionOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecuteTransaction", varargs...)
+	ret0, _ := ret[0].(*dynamodb.ExecuteTransactionOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteTransaction indicates an expected call of ExecuteTransaction.
+func (mr *MockDynamoDBAPIMockRecorder) ExecuteTransaction(ctx, params any, optFns ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteTransaction", reflect.TypeOf((*MockDynamoDBAPI)(nil).ExecuteTransaction), varargs...)
+}
+
+// PutItem mocks base method.
+func (m *MockDynamoDBAPI) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PutItem", varargs...)
+	ret0, _ := ret[0].(*dynamodb.PutItemOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutItem indicates an expected call of PutItem.
+func (mr *MockDynamoDBAPIMockRecorder) PutItem(ctx, params any, optFns ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutItem", reflect.TypeOf((*MockDynamoDBAPI)(nil).PutItem), varargs...)
+}
@@ -0,0 +1,99 @@
+package dynmgrm
+
+import (
+	"gorm.io/gorm/clause"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// reOr matches OR operator in the raw conditions.
+var reOr = regexp.MustCompile(`(?i)\sOR\s`)
+
+// pinningPatterns caches the patterns of the raw conditions that pin the columns, keyed by pinningPatternKey.
+var pinningPatterns sync.Map
+
+// pinningPatternKey is the key of pinningPatterns.
+type pinningPatternKey struct {
+	column   string
+	multiple bool
+}
+
+// pinningPatternOf returns the pattern of the raw conditions that pin the column with =, or IN if multiple is true.
+func pinningPatternOf(column string, multiple bool) *regexp.Regexp {
+	key := pinningPatternKey{column: column, multiple: multiple}
+	if re, ok := pinningPatterns.Load(key); ok {
+		return re.(*regexp.Regexp)
+	}
+	operator := `=\s*[?']`
+	if multiple {
+		operator = `(=\s*[?']|IN\s*[(\[?])`
+	}
+	re, _ := pinningPatterns.LoadOrStore(key, regexp.MustCompile(`(?i)(^|[\s(])"?`+regexp.QuoteMeta(column)+`"?\s*`+operator))
+	return re.(*regexp.Regexp)
+}
+
+// pinsColumn reports whether the conditions of WHERE clause pin the column to a single value with =,
+// or to multiple values with = joined by OR, or IN, if multiple is true.
+// As gorm builds them, OR with a single condition joins the ANDed conditions before and after it,
+// so each of the joined groups must pin the column.
+func pinsColumn(exprs []clause.Expression, column string, multiple bool) bool {
+	groups := [][]clause.Expression{nil}
+	for i, expr := range exprs {
+		if or, ok := expr.(clause.OrConditions); ok && len(or.Exprs) == 1 && i > 0 {
+			if !multiple {
+				return false
+			}
+			groups = append(groups, []clause.Expression{or.Exprs[0]})
+			continue
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], expr)
+	}
+	for _, group := range groups {
+		if !slices.ContainsFunc(group, func(expr clause.Expression) bool { return pinsColumnBy(expr, column, multiple) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// pinsColumnBy reports whether the condition pins the column as pinsColumn does.
+func pinsColumnBy(expr clause.Expression, column string, multiple bool) bool {
+	switch expr := expr.(type) {
+	case clause.Eq:
+		return columnNameOf(expr.Column) == column
+	case clause.IN:
+		if !multiple {
+			return false
+		}
+		if columns, ok := expr.Column.([]clause.Column); ok {
+			// tuples of the primary keys
+			return len(columns) > 0 && columns[0].Name == column
+		}
+		return columnNameOf(expr.Column) == column
+	case clause.Expr:
+		return !reOr.MatchString(expr.SQL) && pinningPatternOf(column, multiple).MatchString(expr.SQL)
+	case clause.AndConditions:
+		return pinsColumn(expr.Exprs, column, multiple)
+	case clause.OrConditions:
+		if !multiple && len(expr.Exprs) > 1 {
+			return false
+		}
+		return len(expr.Exprs) > 0 && !slices.ContainsFunc(expr.Exprs, func(e clause.Expression) bool {
+			return !pinsColumnBy(e, column, multiple)
+		})
+	}
+	return false
+}
+
+// columnNameOf returns the name of the column of the condition.
+func columnNameOf(column interface{}) string {
+	switch c := column.(type) {
+	case clause.Column:
+		return c.Name
+	case string:
+		return strings.Trim(c, `"`)
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package dynmgrm
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"gorm.io/gorm/clause"
+	"testing"
+)
+
+func TestPinsColumn(t *testing.T) {
+	type want struct {
+		single   bool
+		multiple bool
+	}
+	type test struct {
+		exprs []clause.Expression
+		want  want
+	}
+	tests := map[string]test{
+		"eq": {
+			exprs: []clause.Expression{clause.Eq{Column: clause.Column{Name: "pk"}, Value: "Partition1"}},
+			want:  want{single: true, multiple: true},
+		},
+		"raw_eq": {
+			exprs: []clause.Expression{clause.Expr{SQL: `"pk" = ? AND sk > ?`}},
+			want:  want{single: true, multiple: true},
+		},
+		"in": {
+			exprs: []clause.Expression{clause.IN{Column: clause.Column{Name: "pk"}, Values: []interface{}{"Partition1", "Partition2"}}},
+			want:  want{multiple: true},
+		},
+		"raw_in": {
+			exprs: []clause.Expression{clause.Expr{SQL: `pk IN ?`}},
+			want:  want{multiple: true},
+		},
+		"or": {
+			exprs: []clause.Expression{
+				clause.Expr{SQL: `pk = ?`},
+				clause.OrConditions{Exprs: []clause.Expression{clause.Expr{SQL: `pk = ?`}}},
+			},
+			want: want{multiple: true},
+		},
+		"or_without_column": {
+			exprs: []clause.Expression{
+				clause.Expr{SQL: `pk = ?`},
+				clause.OrConditions{Exprs: []clause.Expression{clause.Expr{SQL: `name = ?`}}},
+			},
+		},
+		"raw_or": {
+			exprs: []clause.Expression{clause.Expr{SQL: `pk = ? OR name = ?`}},
+		},
+		"other_column": {
+			exprs: []clause.Expression{clause.Expr{SQL: `pk_prefix = ?`}},
+		},
+		"range": {
+			exprs: []clause.Expression{clause.Gt{Column: clause.Column{Name: "pk"}, Value: "Partition1"}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := want{
+				single:   pinsColumn(tt.exprs, "pk", false),
+				multiple: pinsColumn(tt.exprs, "pk", true),
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("pinsColumn() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPinningPatternOf(t *testing.T) {
+	if pinningPatternOf("pk", true) != pinningPatternOf("pk", true) {
+		t.Errorf("pinningPatternOf() compiled the pattern again")
+	}
+	if pinningPatternOf("pk", true) == pinningPatternOf("pk", false) {
+		t.Errorf("pinningPatternOf() shared the pattern of IN with the one of =")
+	}
+}
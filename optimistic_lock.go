@@ -0,0 +1,197 @@
+package dynmgrm
+
+import (
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"reflect"
+)
+
+// ErrStaleObject occurs when the item has been updated or deleted since it was read.
+var ErrStaleObject = errors.New("the item has been updated or deleted since it was read")
+
+// versionGuardKey is the key of the instance setting that holds the version that the statement expects.
+const versionGuardKey = "dynmgrm:version_guard"
+
+// versionFieldOf returns the field tagged with `dynmgrm:"version"`, or nil if it does not exist.
+func versionFieldOf(sch *schema.Schema) *schema.Field {
+	if sch == nil {
+		return nil
+	}
+	for _, field := range sch.Fields {
+		if !newDynmgrmTag(field.Tag).Version {
+			continue
+		}
+		switch field.FieldType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return field
+		}
+	}
+	return nil
+}
+
+// versionGuardOf returns the version that the statement expects.
+func versionGuardOf(stmt *gorm.Statement) (interface{}, bool) {
+	if stmt.DB == nil || stmt.DB.Statement != stmt {
+		return nil, false
+	}
+	return stmt.DB.InstanceGet(versionGuardKey)
+}
+
+// initVersion sets the version of the items to be created to 1, if it is zero.
+func initVersion(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	field := versionFieldOf(db.Statement.Schema)
+	if field == nil {
+		return
+	}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if _, zero := field.ValueOf(db.Statement.Context, rv.Index(i)); zero {
+				db.AddError(field.Set(db.Statement.Context, rv.Index(i), 1))
+			}
+		}
+	case reflect.Struct:
+		if _, zero := field.ValueOf(db.Statement.Context, rv); zero {
+			db.AddError(field.Set(db.Statement.Context, rv, 1))
+		}
+	}
+}
+
+// guardVersion adds the condition of the version to the statement,
+// and lets buildSetClause increment it.
+//
+// Statements whose model does not have the version, e.g. Model(&T{}).Where(...), are not guarded.
+func guardVersion(db *gorm.DB) {
+	if db.Error != nil || db.Statement.ReflectValue.Kind() != reflect.Struct {
+		return
+	}
+	field := versionFieldOf(db.Statement.Schema)
+	if field == nil {
+		return
+	}
+	version, zero := field.ValueOf(db.Statement.Context, db.Statement.ReflectValue)
+	if zero {
+		return
+	}
+	db.Statement.AddClause(clause.Where{
+		Exprs: []clause.Expression{clause.Eq{Column: clause.Column{Name: field.DBName}, Value: version}},
+	})
+	db.InstanceSet(versionGuardKey, version)
+}
+
+// checkVersion returns the callback that returns ErrStaleObject if the condition of the version has failed.
+// If increment is true, the callback increments the version of the model on success.
+//
+// In transactions begun by the user, the failure is returned on commit.
+func checkVersion(increment bool) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		version, ok := db.InstanceGet(versionGuardKey)
+		if !ok || db.DryRun {
+			return
+		}
+		if versionConditionFailed(db, version) {
+			db.Error = ErrStaleObject
+			return
+		}
+		if db.Error != nil {
+			return
+		}
+		// RowsAffected is unknown in transactions, except for the statements with RETURNING that run outside them.
+		_, defaultTx := db.InstanceGet("gorm:started_transaction")
+		_, userTx := db.Statement.ConnPool.(gorm.TxCommitter)
+		_, returning := db.Statement.Clauses["RETURNING"]
+		if (returning || (!defaultTx && !userTx)) && db.RowsAffected == 0 {
+			db.AddError(ErrStaleObject)
+			return
+		}
+		if increment {
+			incrementVersion(db, version)
+		}
+	}
+}
+
+// incrementVersion sets the version of the model to the next of the current.
+func incrementVersion(db *gorm.DB, version interface{}) {
+	field := versionFieldOf(db.Statement.Schema)
+	rv := reflect.ValueOf(version)
+	var next interface{}
+	switch {
+	case rv.CanInt():
+		next = rv.Int() + 1
+	case rv.CanUint():
+		next = rv.Uint() + 1
+	default:
+		return
+	}
+	if db.Statement.ReflectValue.CanAddr() {
+		db.AddError(field.Set(db.Statement.Context, db.Statement.ReflectValue, next))
+	}
+}
+
+// versionConditionFailed reports whether the error of the statement is caused by the condition of the version.
+//
+// The other conditions of the statement may have failed instead,
+// so the version of the existing item is compared if it is returned with the error.
+// Otherwise, the error is attributed to the version only when the statement has no Condition.
+func versionConditionFailed(db *gorm.DB, version interface{}) bool {
+	if !isConditionalCheckFailed(db.Error) {
+		return false
+	}
+	item := conditionFailedItem(db.Error)
+	if len(item) == 0 {
+		_, conditional := conditionsOf(db.Statement)
+		return !conditional
+	}
+	field := versionFieldOf(db.Statement.Schema)
+	if field == nil {
+		return false
+	}
+	current, ok := item[field.DBName].(*types.AttributeValueMemberN)
+	return !ok || current.Value != fmt.Sprint(version)
+}
+
+// conditionFailedItem returns the existing item returned with the failure of the condition, or nil if it is not returned.
+func conditionFailedItem(err error) map[string]types.AttributeValue {
+	var ccfe *types.ConditionalCheckFailedException
+	if errors.As(err, &ccfe) {
+		return ccfe.Item
+	}
+	var tce *types.TransactionCanceledException
+	if !errors.As(err, &tce) {
+		return nil
+	}
+	for _, reason := range tce.CancellationReasons {
+		if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+			return reason.Item
+		}
+	}
+	return nil
+}
+
+// isConditionalCheckFailed reports whether the error is caused by a failed condition.
+func isConditionalCheckFailed(err error) bool {
+	var ccfe *types.ConditionalCheckFailedException
+	if errors.As(err, &ccfe) || errors.Is(err, ErrConditionFailed) {
+		return true
+	}
+	var tce *types.TransactionCanceledException
+	if !errors.As(err, &tce) {
+		return false
+	}
+	for _, reason := range tce.CancellationReasons {
+		if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,243 @@
+package dynmgrm
+
+import (
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/miyamo2/dynmgrm/internal/mocks"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"testing"
+)
+
+type versionedItem struct {
+	PK      string `gorm:"primaryKey"`
+	SK      int    `gorm:"primaryKey"`
+	Name    string
+	Version int `dynmgrm:"version"`
+}
+
+func TestOptimisticLock(t *testing.T) {
+	type want struct {
+		execs   []partiqlStatement
+		version int
+		err     error
+	}
+	type test struct {
+		item         versionedItem
+		rowsAffected int64
+		commitErr    error
+		config       *gorm.Config
+		operation    func(db *gorm.DB, item *versionedItem) error
+		want         want
+	}
+	save := func(db *gorm.DB, item *versionedItem) error {
+		return db.Save(item).Error
+	}
+	update := func(db *gorm.DB, item *versionedItem) error {
+		return db.Model(item).Update("name", "Updated").Error
+	}
+	canceled := &types.TransactionCanceledException{
+		Message: aws.String("Transaction cancelled"),
+		CancellationReasons: []types.CancellationReason{
+			{Code: aws.String("ConditionalCheckFailed")},
+		},
+	}
+	tests := map[string]test{
+		"happy_path/create": {
+			item:         versionedItem{PK: "Partition1", SK: 1, Name: "Item1"},
+			rowsAffected: 1,
+			operation: func(db *gorm.DB, item *versionedItem) error {
+				return db.Create(item).Error
+			},
+			want: want{
+				execs: []partiqlStatement{
+					{
+						sql:  `INSERT INTO "versioned_items" VALUE {'pk' : ?, 'sk' : ?, 'name' : ?, 'version' : ?}`,
+						vars: []interface{}{"Partition1", 1, "Item1", 1},
+					},
+				},
+				version: 1,
+			},
+		},
+		"happy_path/save": {
+			item:         versionedItem{PK: "Partition1", SK: 1, Name: "Item1", Version: 3},
+			rowsAffected: 1,
+			operation:    save,
+			want: want{
+				execs: []partiqlStatement{
+					{
+						sql:  `UPDATE "versioned_items" SET "name"=? SET "version"="version" + 1 WHERE "version" = ? AND "pk" = ? AND "sk" = ?`,
+						vars: []interface{}{"Item1", 3, "Partition1", 1},
+					},
+				},
+				version: 4,
+			},
+		},
+		"happy_path/update": {
+			item:         versionedItem{PK: "Partition1", SK: 1, Name: "Item1", Version: 3},
+			rowsAffected: 1,
+			operation:    update,
+			want: want{
+				execs: []partiqlStatement{
+					{
+						sql:  `UPDATE "versioned_items" SET "name"=? SET "version"="version" + 1 WHERE "version" = ? AND "pk" = ? AND "sk" = ?`,
+						vars: []interface{}{"Updated", 3, "Partition1", 1},
+					},
+				},
+				version: 4,
+			},
+		},
+		"happy_path/update_without_version": {
+			item:         versionedItem{PK: "Partition1", SK: 1, Name: "Item1"},
+			rowsAffected: 1,
+			operation:    update,
+			want: want{
+				execs: []partiqlStatement{
+					{
+						sql:  `UPDATE "versioned_items" SET "name"=? WHERE "pk" = ? AND "sk" = ?`,
+						vars: []interface{}{"Updated", "Partition1", 1},
+					},
+				},
+				version: 0,
+			},
+		},
+		"happy_path/delete": {
+			item:         versionedItem{PK: "Partition1", SK: 1, Name: "Item1", Version: 3},
+			rowsAffected: 1,
+			operation: func(db *gorm.DB, item *versionedItem) error {
+				return db.Delete(item).Error
+			},
+			want: want{
+				execs: []partiqlStatement{
+					{
+						sql:  `DELETE FROM "versioned_items" WHERE "version" = ? AND "pk" = ? AND "sk" = ?`,
+						vars: []interface{}{3, "Partition1", 1},
+					},
+				},
+				version: 3,
+			},
+		},
+		"happy_path/dry_run": {
+			item:      versionedItem{PK: "Partition1", SK: 1, Name: "Item1", Version: 3},
+			config:    &gorm.Config{SkipDefaultTransaction: true, DryRun: true},
+			operation: update,
+			want: want{
+				version: 3,
+			},
+		},
+		"unhappy_path/update_stale_object": {
+			item:      versionedItem{PK: "Partition1", SK: 1, Name: "Item1", Version: 3},
+			operation: update,
+			want: want{
+				execs: []partiqlStatement{
+					{
+						sql:  `UPDATE "versioned_items" SET "name"=? SET "version"="version" + 1 WHERE "version" = ? AND "pk" = ? AND "sk" = ?`,
+						vars: []interface{}{"Updated", 3, "Partition1", 1},
+					},
+				},
+				version: 3,
+				err:     ErrStaleObject,
+			},
+		},
+		"unhappy_path/delete_stale_object": {
+			item: versionedItem{PK: "Partition1", SK: 1, Name: "Item1", Version: 3},
+			operation: func(db *gorm.DB, item *versionedItem) error {
+				return db.Delete(item).Error
+			},
+			want: want{
+				execs: []partiqlStatement{
+					{
+						sql:  `DELETE FROM "versioned_items" WHERE "version" = ? AND "pk" = ? AND "sk" = ?`,
+						vars: []interface{}{3, "Partition1", 1},
+					},
+				},
+				version: 3,
+				err:     ErrStaleObject,
+			},
+		},
+		"unhappy_path/stale_object_in_default_transaction": {
+			item:      versionedItem{PK: "Partition1", SK: 1, Name: "Item1", Version: 3},
+			commitErr: canceled,
+			config:    &gorm.Config{},
+			operation: update,
+			want: want{
+				execs: []partiqlStatement{
+					{
+						sql:  `UPDATE "versioned_items" SET "name"=? SET "version"="version" + 1 WHERE "version" = ? AND "pk" = ? AND "sk" = ?`,
+						vars: []interface{}{"Updated", 3, "Partition1", 1},
+					},
+				},
+				version: 3,
+				err:     ErrStaleObject,
+			},
+		},
+	}
+	opts := []cmp.Option{
+		cmp.AllowUnexported(partiqlStatement{}),
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool := &fakeConnPool{rowsAffected: tt.rowsAffected, commitErr: tt.commitErr}
+			db := openTestDB(t, pool, nil, tt.config)
+			item := tt.item
+
+			err := tt.operation(db, &item)
+			if !errors.Is(err, tt.want.err) {
+				t.Fatalf("error = %v, want %v", err, tt.want.err)
+			}
+			if diff := cmp.Diff(tt.want.execs, pool.execs, opts...); diff != "" {
+				t.Errorf("executed statements mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.version, item.Version); diff != "" {
+				t.Errorf("Version mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOptimisticLock_withCondition(t *testing.T) {
+	type test struct {
+		err  error
+		want error
+	}
+	failed := func(version string) error {
+		return &types.ConditionalCheckFailedException{
+			Message: aws.String("The conditional request failed"),
+			Item: map[string]types.AttributeValue{
+				"pk":      &types.AttributeValueMemberS{Value: "Partition1"},
+				"sk":      &types.AttributeValueMemberN{Value: "1"},
+				"name":    &types.AttributeValueMemberS{Value: "Item0"},
+				"version": &types.AttributeValueMemberN{Value: version},
+			},
+		}
+	}
+	tests := map[string]test{
+		"unhappy_path/condition_failed": {
+			err:  failed("3"),
+			want: ErrConditionFailed,
+		},
+		"unhappy_path/stale_object": {
+			err:  failed("4"),
+			want: ErrStaleObject,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockDynamoDBAPI(ctrl)
+			client.EXPECT().ExecuteStatement(gomock.Any(), gomock.Any()).Return(nil, tt.err).Times(1)
+			db := openTestDB(t, &fakeConnPool{}, client, nil)
+			item := versionedItem{PK: "Partition1", SK: 1, Name: "Item1", Version: 3}
+
+			err := db.Model(&item).Clauses(Condition(`name = ?`, "Item1")).Update("name", "Updated").Error
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			if diff := cmp.Diff(3, item.Version); diff != "" {
+				t.Errorf("Version mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package dynmgrm
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrOrderByNonKeyAttribute occurs when ORDER BY has an attribute other than the partition key and the sort key.
+	ErrOrderByNonKeyAttribute = errors.New("ORDER BY supports only the partition key and the sort key")
+	// ErrOrderByWithoutPartitionKey occurs when ORDER BY is used without the equality condition of the partition key.
+	ErrOrderByWithoutPartitionKey = errors.New("ORDER BY requires the equality condition of the partition key")
+)
+
+// resolveImplicitOrder resolves the order by the primary key that First/Last add implicitly,
+// since DynamoDB does not accept it as is.
+//
+// If the WHERE clause pins the partition key but not the sort key, it is converted into the order by the sort key,
+// so that Last reads the item with the largest sort key. Otherwise the order of First is removed,
+// and the order of Last adds ErrOrderByWithoutPartitionKey, since the items have no order to reverse.
+// It reports false if the error is added.
+func resolveImplicitOrder(stmt *gorm.Statement) bool {
+	c, ok := stmt.Clauses["ORDER BY"]
+	if !ok {
+		return true
+	}
+	orderBy, ok := c.Expression.(clause.OrderBy)
+	if !ok {
+		return true
+	}
+	i := slices.IndexFunc(orderBy.Columns, func(column clause.OrderByColumn) bool {
+		return column.Column.Name == clause.PrimaryKey
+	})
+	if i < 0 {
+		return true
+	}
+	implicit := orderBy.Columns[i]
+	columns := slices.DeleteFunc(slices.Clone(orderBy.Columns), func(column clause.OrderByColumn) bool {
+		return column.Column.Name == clause.PrimaryKey
+	})
+	if len(columns) == 0 && orderBy.Expression == nil {
+		pk, sk, known := keysOf(stmt)
+		pinned := known && hasPartitionKeyCondition(stmt, pk)
+		switch {
+		case pinned && sk != "" && !hasPartitionKeyCondition(stmt, sk):
+			// the item is not identified by the primary key
+			columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: sk}, Desc: implicit.Desc})
+		case implicit.Desc && !known:
+			stmt.AddError(fmt.Errorf("%w: Last without the keys of the model", ErrDynmgrmAreNotSupported))
+			return false
+		case implicit.Desc && !pinned:
+			stmt.AddError(fmt.Errorf("%w: %s", ErrOrderByWithoutPartitionKey, pk))
+			return false
+		}
+	}
+	if len(columns) == 0 && orderBy.Expression == nil {
+		delete(stmt.Clauses, "ORDER BY")
+		return true
+	}
+	orderBy.Columns = columns
+	c.Expression = orderBy
+	stmt.Clauses["ORDER BY"] = c
+	return true
+}
+
+// buildOrderByClause builds ORDER BY clause
+//
+// The columns are validated with the keys of the table or the secondary index, if the model is known.
+func buildOrderByClause(orderBy clause.OrderBy, stmt *gorm.Statement) {
+	columns := make([]clause.OrderByColumn, 0, len(orderBy.Columns))
+	for _, column := range orderBy.Columns {
+		column.Column.Table = ""
+		columns = append(columns, column)
+	}
+	if pk, sk, ok := keysOf(stmt); ok {
+		for _, column := range columns {
+			for _, name := range orderByColumnNames(column) {
+				if name != pk && name != sk {
+					stmt.AddError(fmt.Errorf("%w: %s", ErrOrderByNonKeyAttribute, name))
+					return
+				}
+			}
+		}
+		if !hasPartitionKeyCondition(stmt, pk) {
+			stmt.AddError(fmt.Errorf("%w: %s", ErrOrderByWithoutPartitionKey, pk))
+			return
+		}
+	}
+	stmt.WriteString("ORDER BY ")
+	clause.OrderBy{Columns: columns, Expression: orderBy.Expression}.Build(stmt)
+}
+
+// orderByColumnNames returns the names of the attributes in the column.
+// Raw columns such as "sk DESC, pk" are split into the names.
+func orderByColumnNames(column clause.OrderByColumn) []string {
+	if !column.Column.Raw {
+		return []string{column.Column.Name}
+	}
+	var names []string
+	for _, part := range strings.Split(column.Column.Name, ",") {
+		if fields := strings.Fields(part); len(fields) > 0 {
+			names = append(names, strings.Trim(fields[0], `"`))
+		}
+	}
+	return names
+}
+
+// keysOf returns the partition key and the sort key of the table, or of the secondary index that the statement queries.
+// If the model of the statement is unknown, ok is false.
+func keysOf(stmt *gorm.Statement) (pk, sk string, ok bool) {
+	if stmt.Schema == nil {
+		return "", "", false
+	}
+	var tablePK, tableSK string
+	for _, field := range stmt.Schema.Fields {
+		tag := newDynmgrmTag(field.Tag)
+		switch {
+		case tag.PK:
+			tablePK = field.DBName
+		case tag.SK:
+			tableSK = field.DBName
+		}
+	}
+	if tablePK == "" && len(stmt.Schema.PrimaryFields) > 0 {
+		tablePK = stmt.Schema.PrimaryFields[0].DBName
+		if len(stmt.Schema.PrimaryFields) > 1 {
+			tableSK = stmt.Schema.PrimaryFields[1].DBName
+		}
+	}
+
+	_, index, isIndex := strings.Cut(stmt.Table, ".")
+	if !isIndex {
+		return tablePK, tableSK, tablePK != ""
+	}
+	for _, field := range stmt.Schema.Fields {
+		for _, property := range newDynmgrmTag(field.Tag).IndexProperty {
+			if property.Name != index {
+				continue
+			}
+			switch {
+			case property.PK:
+				pk = field.DBName
+			case property.SK:
+				sk = field.DBName
+				if property.Kind == secondaryIndexKindLSI {
+					pk = tablePK
+				}
+			}
+		}
+	}
+	return pk, sk, pk != ""
+}
+
+// hasPartitionKeyCondition reports whether the WHERE clause pins the partition key with the equality condition.
+func hasPartitionKeyCondition(stmt *gorm.Statement, pk string) bool {
+	c, ok := stmt.Clauses["WHERE"]
+	if !ok {
+		return false
+	}
+	where, ok := c.Expression.(clause.Where)
+	if !ok {
+		return false
+	}
+	return pinsColumn(where.Exprs, pk, false)
+}
@@ -0,0 +1,205 @@
+package dynmgrm
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"testing"
+)
+
+type orderedItem struct {
+	UserID    string `dynmgrm:"pk"`
+	EventedAt string `dynmgrm:"sk"`
+	Kind      string `dynmgrm:"gsi-pk:kind-evented_at-index"`
+	KindAt    string `dynmgrm:"gsi-sk:kind-evented_at-index"`
+	Name      string
+}
+
+func TestOrderBy(t *testing.T) {
+	type want struct {
+		sql string
+		err error
+	}
+	type test struct {
+		operation func(db *gorm.DB) *gorm.DB
+		want      want
+	}
+	tests := map[string]test{
+		"happy_path/raw": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ?`, "User1").Order("evented_at DESC").Find(&[]orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items" WHERE user_id = ? ORDER BY evented_at DESC`,
+			},
+		},
+		"happy_path/column": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(&orderedItem{UserID: "User1"}).
+					Order(clause.OrderByColumn{Column: clause.Column{Name: "evented_at"}, Desc: true}).
+					Limit(10).
+					Find(&[]orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items" WHERE "user_id" = ? ORDER BY "evented_at" DESC LIMIT 10`,
+			},
+		},
+		"happy_path/partition_key_and_sort_key": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`"user_id" = ? AND evented_at > ?`, "User1", "2024").
+					Order(`user_id, "evented_at" ASC`).
+					Find(&[]orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items" WHERE "user_id" = ? AND evented_at > ? ORDER BY user_id, "evented_at" ASC`,
+			},
+		},
+		"happy_path/secondary_index": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Table("ordered_items").
+					Clauses(SecondaryIndex("kind-evented_at-index")).
+					Where(`kind = ?`, "Login").
+					Order("kind_at DESC").
+					Find(&[]orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items"."kind-evented_at-index" WHERE kind = ? ORDER BY kind_at DESC`,
+			},
+		},
+		"happy_path/first_without_partition_key": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`name = ?`, "Item1").First(&orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items" WHERE name = ? LIMIT 1`,
+			},
+		},
+		"happy_path/first_with_partition_key": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ?`, "User1").First(&orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items" WHERE user_id = ? ORDER BY "evented_at" LIMIT 1`,
+			},
+		},
+		"happy_path/last_with_partition_key": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ?`, "User1").Last(&orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items" WHERE user_id = ? ORDER BY "evented_at" DESC LIMIT 1`,
+			},
+		},
+		"happy_path/last_with_primary_key": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ? AND evented_at = ?`, "User1", "2024").Last(&orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items" WHERE user_id = ? AND evented_at = ? LIMIT 1`,
+			},
+		},
+		"happy_path/last_on_secondary_index": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Table("ordered_items").
+					Clauses(SecondaryIndex("kind-evented_at-index")).
+					Where(`kind = ?`, "Login").
+					Last(&orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items"."kind-evented_at-index" WHERE kind = ? ORDER BY "kind_at" DESC LIMIT 1`,
+			},
+		},
+		"happy_path/last_with_explicit_order": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ?`, "User1").Order("evented_at").Last(&orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items" WHERE user_id = ? ORDER BY evented_at LIMIT 1`,
+			},
+		},
+		"happy_path/unknown_model": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Table("ordered_items").Where(`user_id = ?`, "User1").Order("name").Find(&[]map[string]interface{}{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items" WHERE user_id = ? ORDER BY name`,
+			},
+		},
+		"unhappy_path/non_key_attribute": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ?`, "User1").Order("name").Find(&[]orderedItem{})
+			},
+			want: want{
+				err: ErrOrderByNonKeyAttribute,
+			},
+		},
+		"unhappy_path/table_sort_key_on_secondary_index": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Table("ordered_items").
+					Clauses(SecondaryIndex("kind-evented_at-index")).
+					Where(`kind = ?`, "Login").
+					Order("evented_at").
+					Find(&[]orderedItem{})
+			},
+			want: want{
+				err: ErrOrderByNonKeyAttribute,
+			},
+		},
+		"unhappy_path/without_partition_key": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`evented_at > ?`, "2024").Order("evented_at").Find(&[]orderedItem{})
+			},
+			want: want{
+				err: ErrOrderByWithoutPartitionKey,
+			},
+		},
+		"unhappy_path/partition_key_in_or": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ?`, "User1").Or(`user_id = ?`, "User2").Order("evented_at").Find(&[]orderedItem{})
+			},
+			want: want{
+				err: ErrOrderByWithoutPartitionKey,
+			},
+		},
+		"unhappy_path/last_without_partition_key": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`name = ?`, "Item1").Last(&orderedItem{})
+			},
+			want: want{
+				err: ErrOrderByWithoutPartitionKey,
+			},
+		},
+		"unhappy_path/last_of_unknown_model": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Table("ordered_items").Where(`user_id = ?`, "User1").Last(&map[string]interface{}{})
+			},
+			want: want{
+				err: ErrDynmgrmAreNotSupported,
+			},
+		},
+		"unhappy_path/partition_key_range": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id >= ?`, "User1").Order("evented_at").Find(&[]orderedItem{})
+			},
+			want: want{
+				err: ErrOrderByWithoutPartitionKey,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := openTestDB(t, &fakeConnPool{}, nil, &gorm.Config{SkipDefaultTransaction: true, DryRun: true})
+			result := tt.operation(db)
+			if !errors.Is(result.Error, tt.want.err) {
+				t.Fatalf("error = %v, want %v", result.Error, tt.want.err)
+			}
+			if tt.want.err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.want.sql, result.Statement.SQL.String()); diff != "" {
+				t.Errorf("SQL mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package dynmgrm
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidCursor occurs when the cursor of Page is malformed, or its signature does not match.
+var ErrInvalidCursor = errors.New("the cursor is invalid or has been tampered with")
+
+// compatibility check
+var (
+	_ clause.Interface = (*pageExpression)(nil)
+	_ gorm.Rows        = (*itemRows)(nil)
+)
+
+// pageExpression is a clause.Interface that reads a page of the query results.
+type pageExpression struct {
+	limit  int32
+	cursor string
+	next   string
+}
+
+// Name returns the name of the clause.
+func (p *pageExpression) Name() string {
+	return "PAGE"
+}
+
+// Build does nothing, since the page is passed to DynamoDB as the parameters of ExecuteStatement.
+func (p *pageExpression) Build(_ clause.Builder) {}
+
+// MergeClause merges the pageExpression into the clause.
+func (p *pageExpression) MergeClause(clause *clause.Clause) {
+	clause.Expression = p
+}
+
+// NextCursor returns the cursor of the next page, or an empty string if the query has reached the last page.
+func (p *pageExpression) NextCursor() string {
+	return p.next
+}
+
+// Page reads a page of the query results, which starts from the cursor.
+// An empty cursor reads the first page.
+//
+// limit is the number of items that DynamoDB evaluates,
+// so the page may have fewer items and still have the next cursor when the query has filter conditions.
+//
+// e.g.
+//
+//	page := dynmgrm.Page(20, cursor)
+//	db.Clauses(page).Where(`pk = ?`, "Partition1").Find(&items)
+//	next := page.NextCursor()
+func Page(limit int32, cursor string) *pageExpression {
+	return &pageExpression{limit: limit, cursor: cursor}
+}
+
+// pageOf returns the page of the statement.
+func pageOf(stmt *gorm.Statement) (*pageExpression, bool) {
+	c, ok := stmt.Clauses["PAGE"]
+	if !ok {
+		return nil, false
+	}
+	page, ok := c.Expression.(*pageExpression)
+	return page, ok
+}
+
+// queryPage reads a page by ExecuteStatement, and scans the items into the destination.
+func queryPage(db *gorm.DB, page *pageExpression) {
+	dialector, ok := db.Dialector.(*Dialector)
+	if !ok || dialector.client == nil {
+		db.AddError(ErrDynmgrmAreNotSupported)
+		return
+	}
+	token, err := decodeCursor(page.cursor, dialector.cursorSecret)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	params, err := partiqlStatement{sql: db.Statement.SQL.String(), vars: db.Statement.Vars}.parameters()
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	input := &dynamodb.ExecuteStatementInput{
+		Statement: aws.String(db.Statement.SQL.String()),
+		NextToken: token,
+	}
+	if len(params) > 0 {
+		input.Parameters = params
+	}
+	if page.limit > 0 {
+		input.Limit = aws.Int32(page.limit)
+	}
+	if _, ok := db.Statement.Clauses["WITH"]; ok {
+		input.ConsistentRead = aws.Bool(true)
+	}
+	output, err := dialector.client.ExecuteStatement(db.Statement.Context, input)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	page.next = encodeCursor(output.NextToken, dialector.cursorSecret)
+	gorm.Scan(&timeRows{Rows: newItemRows(output.Items)}, db, 0)
+}
+
+// pageClauses returns the clauses of the query without the ones that are passed as the parameters of ExecuteStatement.
+func pageClauses(clauses []string) []string {
+	return slices.DeleteFunc(slices.Clone(clauses), func(name string) bool {
+		return name == "LIMIT" || name == "WITH"
+	})
+}
+
+// encodeCursor encodes NextToken to the cursor, and signs it with HMAC-SHA256 if the secret is set.
+func encodeCursor(token *string, secret []byte) string {
+	if token == nil {
+		return ""
+	}
+	cursor := base64.RawURLEncoding.EncodeToString([]byte(*token))
+	if len(secret) == 0 {
+		return cursor
+	}
+	return cursor + "." + base64.RawURLEncoding.EncodeToString(signCursor(*token, secret))
+}
+
+// decodeCursor decodes the cursor to NextToken, and verifies its signature if the secret is set.
+func decodeCursor(cursor string, secret []byte) (*string, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	encodedToken, encodedSignature, signed := strings.Cut(cursor, ".")
+	token, err := base64.RawURLEncoding.DecodeString(encodedToken)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if len(secret) == 0 {
+		return aws.String(string(token)), nil
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if !signed || err != nil || !hmac.Equal(signature, signCursor(string(token), secret)) {
+		return nil, ErrInvalidCursor
+	}
+	return aws.String(string(token)), nil
+}
+
+// signCursor returns the HMAC-SHA256 of NextToken.
+func signCursor(token string, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(token))
+	return mac.Sum(nil)
+}
+
+// itemRows is a gorm.Rows that reads the items returned by ExecuteStatement.
+type itemRows struct {
+	items   []map[string]types.AttributeValue
+	columns []string
+	cursor  int
+}
+
+// newItemRows returns itemRows whose columns are all attributes of the items, sorted by name as godynamo does.
+func newItemRows(items []map[string]types.AttributeValue) *itemRows {
+	columns := make([]string, 0)
+	for _, item := range items {
+		for column := range item {
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return &itemRows{items: items, columns: columns}
+}
+
+// Columns implements gorm.Rows.
+func (r *itemRows) Columns() ([]string, error) {
+	return r.columns, nil
+}
+
+// ColumnTypes implements gorm.Rows.
+func (r *itemRows) ColumnTypes() ([]*sql.ColumnType, error) {
+	return nil, nil
+}
+
+// Next implements gorm.Rows.
+func (r *itemRows) Next() bool {
+	if r.cursor >= len(r.items) {
+		return false
+	}
+	r.cursor++
+	return true
+}
+
+// Scan implements gorm.Rows.
+func (r *itemRows) Scan(dest ...interface{}) error {
+	if r.cursor == 0 || r.cursor > len(r.items) {
+		return errors.New("Scan called without calling Next")
+	}
+	item := r.items[r.cursor-1]
+	for i, column := range r.columns {
+		if i >= len(dest) {
+			break
+		}
+		var value interface{}
+		if av, ok := item[column]; ok {
+			if err := attributevalue.Unmarshal(av, &value); err != nil {
+				return fmt.Errorf("error unmarshalling %d-th column: %w", i+1, err)
+			}
+		}
+		if err := assignValue(dest[i], value); err != nil {
+			return fmt.Errorf("error scanning %d-th column: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Err implements gorm.Rows.
+func (r *itemRows) Err() error {
+	return nil
+}
+
+// Close implements gorm.Rows.
+func (r *itemRows) Close() error {
+	return nil
+}
+
+// assignValue assigns the value unmarshalled from the attribute to the destination, like database/sql does.
+func assignValue(dest interface{}, value interface{}) error {
+	switch d := dest.(type) {
+	case *interface{}:
+		*d = value
+		return nil
+	case sql.Scanner:
+		return d.Scan(value)
+	}
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("destination not a pointer: %T", dest)
+	}
+	rv = rv.Elem()
+	if value == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	if rv.Kind() == reflect.Pointer {
+		rv.Set(reflect.New(rv.Type().Elem()))
+		return assignValue(rv.Interface(), value)
+	}
+	sv := reflect.ValueOf(value)
+	switch {
+	case sv.Type().AssignableTo(rv.Type()):
+		rv.Set(sv)
+	case isNumber(sv.Kind()) && isNumber(rv.Kind()), sv.Kind() == rv.Kind() && sv.CanConvert(rv.Type()):
+		rv.Set(sv.Convert(rv.Type()))
+	default:
+		return fmt.Errorf("unsupported Scan, storing %T into type %T", value, dest)
+	}
+	return nil
+}
+
+// isNumber reports whether the kind is a number.
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
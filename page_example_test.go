@@ -0,0 +1,27 @@
+package dynmgrm_test
+
+import (
+	"github.com/miyamo2/dynmgrm"
+	"gorm.io/gorm"
+)
+
+func ExamplePage() {
+	db, err := gorm.Open(dynmgrm.New(dynmgrm.WithCursorSecret([]byte("secret"))), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+
+	var cursor string
+	for {
+		var items []TestTable
+		page := dynmgrm.Page(20, cursor)
+		db.Clauses(page).
+			Where(`pk = ?`, "Partition1").
+			Find(&items)
+
+		cursor = page.NextCursor()
+		if cursor == "" {
+			break
+		}
+	}
+}
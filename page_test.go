@@ -0,0 +1,245 @@
+package dynmgrm
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/miyamo2/dynmgrm/internal/mocks"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+type pageItem struct {
+	PK        string `gorm:"primaryKey"`
+	SK        int    `gorm:"primaryKey"`
+	Name      *string
+	CreatedAt time.Time
+}
+
+func TestPage(t *testing.T) {
+	type want struct {
+		input      *dynamodb.ExecuteStatementInput
+		items      []pageItem
+		nextCursor string
+		err        error
+	}
+	type test struct {
+		cursor    string
+		secret    []byte
+		operation func(db *gorm.DB, page *pageExpression, items *[]pageItem) *gorm.DB
+		output    *dynamodb.ExecuteStatementOutput
+		want      want
+	}
+	find := func(db *gorm.DB, page *pageExpression, items *[]pageItem) *gorm.DB {
+		return db.Clauses(page).Where(`pk = ?`, "Partition1").Find(items)
+	}
+	secret := []byte("secret")
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	output := &dynamodb.ExecuteStatementOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				"pk":         &types.AttributeValueMemberS{Value: "Partition1"},
+				"sk":         &types.AttributeValueMemberN{Value: "1"},
+				"name":       &types.AttributeValueMemberS{Value: "Item1"},
+				"created_at": &types.AttributeValueMemberS{Value: "2024-01-02T03:04:05Z"},
+			},
+			{
+				"pk": &types.AttributeValueMemberS{Value: "Partition1"},
+				"sk": &types.AttributeValueMemberN{Value: "2"},
+			},
+		},
+		NextToken: aws.String("token2"),
+	}
+	items := []pageItem{
+		{PK: "Partition1", SK: 1, Name: aws.String("Item1"), CreatedAt: createdAt},
+		{PK: "Partition1", SK: 2},
+	}
+	tests := map[string]test{
+		"happy_path/first_page": {
+			operation: find,
+			output:    output,
+			want: want{
+				input: &dynamodb.ExecuteStatementInput{
+					Statement:  aws.String(`SELECT * FROM "page_items" WHERE pk = ?`),
+					Parameters: []types.AttributeValue{&types.AttributeValueMemberS{Value: "Partition1"}},
+					Limit:      aws.Int32(2),
+				},
+				items:      items,
+				nextCursor: encodeCursor(aws.String("token2"), nil),
+			},
+		},
+		"happy_path/next_page": {
+			cursor:    encodeCursor(aws.String("token1"), nil),
+			operation: find,
+			output:    &dynamodb.ExecuteStatementOutput{Items: output.Items},
+			want: want{
+				input: &dynamodb.ExecuteStatementInput{
+					Statement:  aws.String(`SELECT * FROM "page_items" WHERE pk = ?`),
+					Parameters: []types.AttributeValue{&types.AttributeValueMemberS{Value: "Partition1"}},
+					Limit:      aws.Int32(2),
+					NextToken:  aws.String("token1"),
+				},
+				items: items,
+			},
+		},
+		"happy_path/signed_cursor": {
+			cursor:    encodeCursor(aws.String("token1"), secret),
+			secret:    secret,
+			operation: find,
+			output:    output,
+			want: want{
+				input: &dynamodb.ExecuteStatementInput{
+					Statement:  aws.String(`SELECT * FROM "page_items" WHERE pk = ?`),
+					Parameters: []types.AttributeValue{&types.AttributeValueMemberS{Value: "Partition1"}},
+					Limit:      aws.Int32(2),
+					NextToken:  aws.String("token1"),
+				},
+				items:      items,
+				nextCursor: encodeCursor(aws.String("token2"), secret),
+			},
+		},
+		"happy_path/with_limit_and_consistent_read": {
+			operation: func(db *gorm.DB, page *pageExpression, items *[]pageItem) *gorm.DB {
+				return db.Clauses(page, ConsistentRead()).Where(`pk = ?`, "Partition1").Limit(10).Find(items)
+			},
+			output: output,
+			want: want{
+				input: &dynamodb.ExecuteStatementInput{
+					Statement:      aws.String(`SELECT * FROM "page_items" WHERE pk = ?`),
+					Parameters:     []types.AttributeValue{&types.AttributeValueMemberS{Value: "Partition1"}},
+					Limit:          aws.Int32(2),
+					ConsistentRead: aws.Bool(true),
+				},
+				items:      items,
+				nextCursor: encodeCursor(aws.String("token2"), nil),
+			},
+		},
+		"unhappy_path/unsigned_cursor": {
+			cursor:    encodeCursor(aws.String("token1"), nil),
+			secret:    secret,
+			operation: find,
+			want: want{
+				err: ErrInvalidCursor,
+			},
+		},
+		"unhappy_path/tampered_cursor": {
+			cursor:    encodeCursor(aws.String("token1"), []byte("another secret")),
+			secret:    secret,
+			operation: find,
+			want: want{
+				err: ErrInvalidCursor,
+			},
+		},
+		"unhappy_path/malformed_cursor": {
+			cursor:    "!",
+			operation: find,
+			want: want{
+				err: ErrInvalidCursor,
+			},
+		},
+	}
+	opts := []cmp.Option{
+		cmpopts.IgnoreUnexported(
+			dynamodb.ExecuteStatementInput{},
+			types.AttributeValueMemberS{},
+		),
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockDynamoDBAPI(ctrl)
+			var input *dynamodb.ExecuteStatementInput
+			if tt.output != nil {
+				client.EXPECT().ExecuteStatement(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *dynamodb.ExecuteStatementInput, _ ...func(*dynamodb.Options)) (*dynamodb.ExecuteStatementOutput, error) {
+						input = in
+						return tt.output, nil
+					}).Times(1)
+			}
+			db := openTestDB(t, &fakeConnPool{}, client, nil)
+			db.Dialector.(*Dialector).cursorSecret = tt.secret
+
+			page := Page(2, tt.cursor)
+			var got []pageItem
+			err := tt.operation(db, page, &got).Error
+			if !errors.Is(err, tt.want.err) {
+				t.Fatalf("Find() error = %v, want %v", err, tt.want.err)
+			}
+			if diff := cmp.Diff(tt.want.input, input, opts...); diff != "" {
+				t.Errorf("ExecuteStatementInput mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.items, got); diff != "" {
+				t.Errorf("items mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.nextCursor, page.NextCursor()); diff != "" {
+				t.Errorf("NextCursor mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLimit(t *testing.T) {
+	db := openTestDB(t, &fakeConnPool{}, nil, &gorm.Config{SkipDefaultTransaction: true, DryRun: true})
+	got := db.Clauses(ConsistentRead()).Where(`pk = ?`, "Partition1").Limit(10).Find(&[]testItem{}).Statement.SQL.String()
+	want := `SELECT * FROM "test_items" WHERE pk = ? LIMIT 10 WITH ConsistentRead=true`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SQL mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_decodeCursor(t *testing.T) {
+	type test struct {
+		cursor string
+		secret []byte
+		want   *string
+		err    error
+	}
+	secret := []byte("secret")
+	tests := map[string]test{
+		"happy_path/empty": {},
+		"happy_path/unsigned": {
+			cursor: encodeCursor(aws.String("token"), nil),
+			want:   aws.String("token"),
+		},
+		"happy_path/signed": {
+			cursor: encodeCursor(aws.String("token"), secret),
+			secret: secret,
+			want:   aws.String("token"),
+		},
+		"happy_path/signed_without_secret": {
+			cursor: encodeCursor(aws.String("token"), secret),
+			want:   aws.String("token"),
+		},
+		"unhappy_path/without_signature": {
+			cursor: encodeCursor(aws.String("token"), nil),
+			secret: secret,
+			err:    ErrInvalidCursor,
+		},
+		"unhappy_path/wrong_signature": {
+			cursor: encodeCursor(aws.String("token"), []byte("another secret")),
+			secret: secret,
+			err:    ErrInvalidCursor,
+		},
+		"unhappy_path/malformed": {
+			cursor: "!",
+			err:    ErrInvalidCursor,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := decodeCursor(tt.cursor, tt.secret)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("decodeCursor() error = %v, want %v", err, tt.err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("decodeCursor() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -16,14 +16,39 @@ var (
 
 var (
 	_ functionForPartiQLUpdates = (*listAppend)(nil)
+	_ functionForPartiQLUpdates = (*listPrepend)(nil)
+	_ functionForPartiQLUpdates = (*setFunction)(nil)
+	_ functionForPartiQLUpdates = (*arithmetic)(nil)
 )
 
 // functionForPartiQLUpdates is an interface for PartiQL functions that can be used in updates.
 type functionForPartiQLUpdates interface {
-	// expression returns a string that represents the function in the SQL query.
+	// expression returns a string that represents the function in the SQL query, up to the bind variable.
 	expression(db *gorm.DB, column string) string
-	// bindVariable returns a gorm.Valuer that represents the bind variable for the function.
-	bindVariable() driver.Valuer
+	// bindVariable returns the bind variable for the function.
+	bindVariable() interface{}
+	// closing returns a string that follows the bind variable.
+	closing(db *gorm.DB, column string) string
+}
+
+// validColumnName reports whether the column name can be written in the expression,
+// and adds ErrInvalidColumnName to db if not.
+func validColumnName(db *gorm.DB, column string) bool {
+	if !reInvalidColumnName.MatchString(column) {
+		db.AddError(ErrInvalidColumnName)
+		return false
+	}
+	return true
+}
+
+// quotedAttribute returns the attribute quoted in the same way as the left side of the assignment.
+func quotedAttribute(db *gorm.DB, column string) string {
+	stmt := &gorm.Statement{DB: db}
+	if db.Statement != nil {
+		stmt.Schema = db.Statement.Schema
+	}
+	writeAttribute(stmt, column)
+	return stmt.SQL.String()
 }
 
 // listAppend is a struct that implements functionForPartiQLUpdates interface for `list_append` function.
@@ -32,18 +57,135 @@ type listAppend struct {
 }
 
 func (la *listAppend) expression(db *gorm.DB, column string) string {
-	if !reInvalidColumnName.MatchString(column) {
-		db.AddError(ErrInvalidColumnName)
+	if !validColumnName(db, column) {
 		return ""
 	}
-	return fmt.Sprintf("list_append(%s, ", column)
+	return fmt.Sprintf("list_append(%s, ", quotedAttribute(db, column))
 }
 
-func (la *listAppend) bindVariable() driver.Valuer {
+func (la *listAppend) bindVariable() interface{} {
 	return la.value
 }
 
+func (la *listAppend) closing(_ *gorm.DB, _ string) string {
+	return ")"
+}
+
 // ListAppend returns a functionForPartiQLUpdates implementation for `list_append` function.
 func ListAppend(item ...interface{}) *listAppend {
 	return &listAppend{value: item}
 }
+
+// listPrepend is a struct that implements functionForPartiQLUpdates interface for `list_append` function,
+// which adds the items to the head of the list.
+type listPrepend struct {
+	value sqldav.List
+}
+
+func (lp *listPrepend) expression(db *gorm.DB, column string) string {
+	if !validColumnName(db, column) {
+		return ""
+	}
+	return "list_append("
+}
+
+func (lp *listPrepend) bindVariable() interface{} {
+	return lp.value
+}
+
+func (lp *listPrepend) closing(db *gorm.DB, column string) string {
+	return fmt.Sprintf(", %s)", quotedAttribute(db, column))
+}
+
+// ListPrepend returns a functionForPartiQLUpdates implementation for `list_append` function,
+// which adds the items to the head of the list.
+func ListPrepend(item ...interface{}) *listPrepend {
+	return &listPrepend{value: item}
+}
+
+// setFunction is a struct that implements functionForPartiQLUpdates interface for `set_add` and `set_delete` functions.
+type setFunction struct {
+	name  string
+	value driver.Valuer
+}
+
+func (sf *setFunction) expression(db *gorm.DB, column string) string {
+	if !validColumnName(db, column) {
+		return ""
+	}
+	return fmt.Sprintf("%s(%s, ", sf.name, quotedAttribute(db, column))
+}
+
+func (sf *setFunction) bindVariable() interface{} {
+	return sf.value
+}
+
+func (sf *setFunction) closing(_ *gorm.DB, _ string) string {
+	return ")"
+}
+
+// SetAdd returns a functionForPartiQLUpdates implementation for `set_add` function.
+//
+// e.g. db.Model(&item).Update("tags", dynmgrm.SetAdd("foo", "bar"))
+func SetAdd[T sqldav.SetSupportable](values ...T) *setFunction {
+	return &setFunction{name: "set_add", value: sqldav.Set[T](values)}
+}
+
+// SetDelete returns a functionForPartiQLUpdates implementation for `set_delete` function.
+//
+// e.g. db.Model(&item).Update("tags", dynmgrm.SetDelete("foo"))
+func SetDelete[T sqldav.SetSupportable](values ...T) *setFunction {
+	return &setFunction{name: "set_delete", value: sqldav.Set[T](values)}
+}
+
+// number is a constraint for the numbers of DynamoDB.
+type number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// arithmetic is a struct that implements functionForPartiQLUpdates interface for `+` and `-` operators.
+type arithmetic struct {
+	operator string
+	value    interface{}
+}
+
+func (a *arithmetic) expression(db *gorm.DB, column string) string {
+	if !validColumnName(db, column) {
+		return ""
+	}
+	return fmt.Sprintf("%s %s ", quotedAttribute(db, column), a.operator)
+}
+
+func (a *arithmetic) bindVariable() interface{} {
+	return a.value
+}
+
+func (a *arithmetic) closing(_ *gorm.DB, _ string) string {
+	return ""
+}
+
+// Increment returns a functionForPartiQLUpdates implementation that adds n to the number attribute.
+//
+// e.g. db.Model(&item).Update("count", dynmgrm.Increment(1))
+func Increment[T number](n T) *arithmetic {
+	return &arithmetic{operator: "+", value: n}
+}
+
+// Decrement returns a functionForPartiQLUpdates implementation that subtracts n from the number attribute.
+//
+// e.g. db.Model(&item).Update("count", dynmgrm.Decrement(1))
+func Decrement[T number](n T) *arithmetic {
+	return &arithmetic{operator: "-", value: n}
+}
+
+// removeAttribute is a value that removes the attribute in updates.
+type removeAttribute struct{}
+
+// Remove returns a value that removes the attribute with REMOVE clause instead of SET clause.
+//
+// e.g. db.Model(&item).Update("attr", dynmgrm.Remove())
+func Remove() removeAttribute {
+	return removeAttribute{}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/miyamo2/dynmgrm"
 	"github.com/miyamo2/sqldav"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log"
 )
 
@@ -20,3 +21,106 @@ func ExampleListAppend() {
 		Update("list_type_attr",
 			dynmgrm.ListAppend(sqldav.Map{"Foo": "Bar"}))
 }
+
+func ExampleListPrepend() {
+	db, err := gorm.Open(
+		dynmgrm.New(),
+		&gorm.Config{
+			SkipDefaultTransaction: true,
+		})
+	if err != nil {
+		log.Fatalf("failed to open database, got error %v", err)
+	}
+	db.Model(&TestTable{PK: "Partition1", SK: 1}).
+		Update("list_type_attr",
+			dynmgrm.ListPrepend(sqldav.Map{"Foo": "Bar"}))
+}
+
+func ExampleSetAdd() {
+	db, err := gorm.Open(
+		dynmgrm.New(),
+		&gorm.Config{
+			SkipDefaultTransaction: true,
+		})
+	if err != nil {
+		log.Fatalf("failed to open database, got error %v", err)
+	}
+	db.Model(&TestTable{PK: "Partition1", SK: 1}).
+		Update("string_set_attr", dynmgrm.SetAdd("Foo", "Bar"))
+}
+
+func ExampleSetDelete() {
+	db, err := gorm.Open(
+		dynmgrm.New(),
+		&gorm.Config{
+			SkipDefaultTransaction: true,
+		})
+	if err != nil {
+		log.Fatalf("failed to open database, got error %v", err)
+	}
+	db.Model(&TestTable{PK: "Partition1", SK: 1}).
+		Update("string_set_attr", dynmgrm.SetDelete("Foo"))
+}
+
+func ExampleIncrement() {
+	db, err := gorm.Open(
+		dynmgrm.New(),
+		&gorm.Config{
+			SkipDefaultTransaction: true,
+		})
+	if err != nil {
+		log.Fatalf("failed to open database, got error %v", err)
+	}
+	db.Model(&TestTable{PK: "Partition1", SK: 1}).
+		Update("number_attr", dynmgrm.Increment(1))
+}
+
+func ExampleDecrement() {
+	db, err := gorm.Open(
+		dynmgrm.New(),
+		&gorm.Config{
+			SkipDefaultTransaction: true,
+		})
+	if err != nil {
+		log.Fatalf("failed to open database, got error %v", err)
+	}
+	db.Model(&TestTable{PK: "Partition1", SK: 1}).
+		Update("number_attr", dynmgrm.Decrement(1))
+}
+
+func ExampleRemove() {
+	db, err := gorm.Open(
+		dynmgrm.New(),
+		&gorm.Config{
+			SkipDefaultTransaction: true,
+		})
+	if err != nil {
+		log.Fatalf("failed to open database, got error %v", err)
+	}
+	db.Model(&TestTable{PK: "Partition1", SK: 1}).
+		Update("gsi_key", dynmgrm.Remove())
+}
+
+func ExampleBeginsWith() {
+	db, err := gorm.Open(dynmgrm.New(), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("failed to open database, got error %v", err)
+	}
+	var result []TestTable
+	db.Where(`pk = ?`, "Partition1").
+		Where(dynmgrm.BeginsWith("gsi_key", "2024-")).
+		Find(&result)
+}
+
+func ExampleSize() {
+	db, err := gorm.Open(dynmgrm.New(), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("failed to open database, got error %v", err)
+	}
+	var result []TestTable
+	db.Where(`pk = ?`, "Partition1").
+		Where(clause.Or(
+			dynmgrm.Size("list_type_attr").Gt(2),
+			dynmgrm.AttributeType("list_type_attr", dynmgrm.TypeStringSet))).
+		Find(&result)
+}
@@ -24,18 +24,18 @@ func Test_listAppend_expression(t *testing.T) {
 		"happy_path": {
 			args: args{
 				db: &gorm.DB{
-					Config: &gorm.Config{},
+					Config: &gorm.Config{Dialector: Dialector{}},
 				},
 				column: "A",
 			},
 			want: want{
-				xp: "list_append(A, ",
+				xp: `list_append("A", `,
 			},
 		},
 		"unhappy_path": {
 			args: args{
 				db: &gorm.DB{
-					Config: &gorm.Config{},
+					Config: &gorm.Config{Dialector: Dialector{}},
 				},
 				column: "A==true",
 			},
@@ -58,3 +58,103 @@ func Test_listAppend_expression(t *testing.T) {
 		})
 	}
 }
+
+func Test_functionForPartiQLUpdates(t *testing.T) {
+	type want struct {
+		sql string
+		err error
+	}
+	type test struct {
+		fn     functionForPartiQLUpdates
+		column string
+		want   want
+	}
+	tests := map[string]test{
+		"happy_path/list_append": {
+			fn:     ListAppend("a"),
+			column: "A",
+			want: want{
+				sql: `list_append("A", ?)`,
+			},
+		},
+		"happy_path/list_prepend": {
+			fn:     ListPrepend("a"),
+			column: "A",
+			want: want{
+				sql: `list_append(?, "A")`,
+			},
+		},
+		"happy_path/set_add": {
+			fn:     SetAdd("a"),
+			column: "A",
+			want: want{
+				sql: `set_add("A", ?)`,
+			},
+		},
+		"happy_path/set_delete": {
+			fn:     SetDelete(1, 2),
+			column: "A",
+			want: want{
+				sql: `set_delete("A", ?)`,
+			},
+		},
+		"happy_path/increment": {
+			fn:     Increment(1),
+			column: "A",
+			want: want{
+				sql: `"A" + ?`,
+			},
+		},
+		"happy_path/decrement": {
+			fn:     Decrement(1.5),
+			column: "A",
+			want: want{
+				sql: `"A" - ?`,
+			},
+		},
+		"happy_path/increment_nested_attribute": {
+			fn:     Increment(1),
+			column: "stats.count",
+			want: want{
+				sql: `"stats"."count" + ?`,
+			},
+		},
+		"unhappy_path/list_prepend": {
+			fn:     ListPrepend("a"),
+			column: "A==true",
+			want: want{
+				err: ErrInvalidColumnName,
+			},
+		},
+		"unhappy_path/set_add": {
+			fn:     SetAdd("a"),
+			column: "A==true",
+			want: want{
+				err: ErrInvalidColumnName,
+			},
+		},
+		"unhappy_path/increment": {
+			fn:     Increment(1),
+			column: "A==true",
+			want: want{
+				err: ErrInvalidColumnName,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := &gorm.DB{Config: &gorm.Config{Dialector: Dialector{}}}
+			got := tt.fn.expression(db, tt.column)
+			if !errors.Is(db.Error, tt.want.err) {
+				t.Fatalf("expression() error = %v, want %v", db.Error, tt.want.err)
+			}
+			if db.Error != nil {
+				return
+			}
+			got += "?" + tt.fn.closing(db, tt.column)
+			if diff := cmp.Diff(tt.want.sql, got); diff != "" {
+				t.Errorf("expression() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
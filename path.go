@@ -0,0 +1,150 @@
+package dynmgrm
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidAttributePath occurs when the path to the nested attribute is malformed.
+	ErrInvalidAttributePath = errors.New("invalid attribute path")
+	// reIdentifier matches the attribute names that need not be quoted in paths.
+	reIdentifier = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+)
+
+// Path returns the path to the nested attribute, that can be passed to Update and Updates as the column.
+// The segments of string are the names of the attributes or the keys of the maps,
+// and the ones of int are the indexes of the lists.
+//
+// e.g.
+//
+//	db.Model(&user).Update(dynmgrm.Path("profile", "address", "city"), "Tokyo") // SET "profile"."address"."city"=?
+//	db.Model(&user).Update(dynmgrm.Path("tags", 2), "go")                       // SET "tags"[2]=?
+//
+// The paths written with dots and brackets, such as `profile.address.city` and `tags[2]`, are also accepted.
+func Path(name string, segments ...interface{}) string {
+	var sb strings.Builder
+	writePathName(&sb, name)
+	for _, segment := range segments {
+		switch segment := segment.(type) {
+		case int:
+			sb.WriteString("[" + strconv.Itoa(segment) + "]")
+		case string:
+			sb.WriteByte('.')
+			writePathName(&sb, segment)
+		default:
+			sb.WriteString(fmt.Sprintf("[%v]", segment))
+		}
+	}
+	return sb.String()
+}
+
+// writePathName writes the name of the path, which is quoted unless it is an identifier.
+func writePathName(sb *strings.Builder, name string) {
+	if reIdentifier.MatchString(name) {
+		sb.WriteString(name)
+		return
+	}
+	sb.WriteString(`"` + name + `"`)
+}
+
+// pathSegment is a segment of the path to the nested attribute.
+type pathSegment struct {
+	// name is the name of the attribute or the key of the map.
+	name string
+	// index is the index of the list, which is valid if name is empty.
+	index int
+}
+
+// parsePath parses the path to the nested attribute, written with dots, brackets and double quotes.
+func parsePath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	invalid := fmt.Errorf("%w: %s", ErrInvalidAttributePath, path)
+	for i, expectName := 0, true; i < len(path); {
+		switch c := path[i]; {
+		case c == '"' && expectName:
+			// quoted names, which may contain dots and brackets
+			j := strings.IndexByte(path[i+1:], '"')
+			if j <= 0 {
+				return nil, invalid
+			}
+			segments = append(segments, pathSegment{name: path[i+1 : i+1+j]})
+			i, expectName = i+j+2, false
+		case c == '[' && !expectName:
+			j := strings.IndexByte(path[i:], ']')
+			if j < 0 {
+				return nil, invalid
+			}
+			index, err := strconv.Atoi(path[i+1 : i+j])
+			if err != nil || index < 0 {
+				return nil, invalid
+			}
+			segments = append(segments, pathSegment{index: index})
+			i += j + 1
+		case c == '.' && !expectName:
+			i, expectName = i+1, true
+		case expectName:
+			j := i
+			for j < len(path) && path[j] != '.' && path[j] != '[' {
+				j++
+			}
+			if !reInvalidColumnName.MatchString(path[i:j]) {
+				return nil, invalid
+			}
+			segments = append(segments, pathSegment{name: path[i:j]})
+			i, expectName = j, false
+		default:
+			return nil, invalid
+		}
+		if i == len(path) && expectName {
+			return nil, invalid
+		}
+	}
+	if len(segments) == 0 {
+		return nil, invalid
+	}
+	return segments, nil
+}
+
+// isAttributePath reports whether the column is the path to the nested attribute, not a field of the model.
+func isAttributePath(builder clause.Builder, column string) bool {
+	if !strings.ContainsAny(column, `.["`) {
+		return false
+	}
+	if stmt, ok := builder.(*gorm.Statement); ok && stmt.Schema != nil {
+		if _, ok := stmt.Schema.FieldsByDBName[column]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// writeAttribute writes the quoted attribute, or the path to the nested attribute.
+// It reports false if the path is malformed.
+func writeAttribute(builder clause.Builder, column string) bool {
+	if !isAttributePath(builder, column) {
+		builder.WriteQuoted(column)
+		return true
+	}
+	segments, err := parsePath(column)
+	if err != nil {
+		_ = builder.AddError(err)
+		return false
+	}
+	for i, segment := range segments {
+		if segment.name == "" {
+			builder.WriteString("[" + strconv.Itoa(segment.index) + "]")
+			continue
+		}
+		if i > 0 {
+			builder.WriteByte('.')
+		}
+		builder.WriteQuoted(segment.name)
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package dynmgrm
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	type test struct {
+		name     string
+		segments []interface{}
+		want     string
+	}
+	tests := map[string]test{
+		"happy_path/attribute": {
+			name: "profile",
+			want: "profile",
+		},
+		"happy_path/map_keys": {
+			name:     "profile",
+			segments: []interface{}{"address", "city"},
+			want:     "profile.address.city",
+		},
+		"happy_path/list_indexes": {
+			name:     "matrix",
+			segments: []interface{}{1, 2},
+			want:     "matrix[1][2]",
+		},
+		"happy_path/quoted": {
+			name:     "profile",
+			segments: []interface{}{"zip.code", "phone-numbers", 0},
+			want:     `profile."zip.code"."phone-numbers"[0]`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := Path(tt.name, tt.segments...)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Path() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_parsePath(t *testing.T) {
+	type want struct {
+		segments []pathSegment
+		err      error
+	}
+	type test struct {
+		path string
+		want want
+	}
+	tests := map[string]test{
+		"happy_path/map_keys": {
+			path: "profile.address.city",
+			want: want{
+				segments: []pathSegment{{name: "profile"}, {name: "address"}, {name: "city"}},
+			},
+		},
+		"happy_path/list_indexes": {
+			path: "tags[2]",
+			want: want{
+				segments: []pathSegment{{name: "tags"}, {index: 2}},
+			},
+		},
+		"happy_path/quoted": {
+			path: `profile."zip.code"[0]`,
+			want: want{
+				segments: []pathSegment{{name: "profile"}, {name: "zip.code"}, {index: 0}},
+			},
+		},
+		"unhappy_path/trailing_dot": {
+			path: "profile.",
+			want: want{
+				err: ErrInvalidAttributePath,
+			},
+		},
+		"unhappy_path/leading_index": {
+			path: "[0]",
+			want: want{
+				err: ErrInvalidAttributePath,
+			},
+		},
+		"unhappy_path/negative_index": {
+			path: "tags[-1]",
+			want: want{
+				err: ErrInvalidAttributePath,
+			},
+		},
+		"unhappy_path/unclosed_index": {
+			path: "tags[0",
+			want: want{
+				err: ErrInvalidAttributePath,
+			},
+		},
+		"unhappy_path/unclosed_quote": {
+			path: `profile."zip`,
+			want: want{
+				err: ErrInvalidAttributePath,
+			},
+		},
+		"unhappy_path/invalid_characters": {
+			path: "profile.city==1",
+			want: want{
+				err: ErrInvalidAttributePath,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parsePath(tt.path)
+			if !errors.Is(err, tt.want.err) {
+				t.Fatalf("parsePath() error = %v, want %v", err, tt.want.err)
+			}
+			if diff := cmp.Diff(tt.want.segments, got, cmp.AllowUnexported(pathSegment{})); diff != "" {
+				t.Errorf("parsePath() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
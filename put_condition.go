@@ -0,0 +1,761 @@
+package dynmgrm
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/miyamo2/godynamo"
+	"gorm.io/gorm/clause"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// putCondition is the ConditionExpression of PutItem with its names and values.
+type putCondition struct {
+	expression string
+	names      map[string]string
+	values     map[string]types.AttributeValue
+}
+
+// newPutCondition translates the conditions to the ConditionExpression of PutItem.
+//
+// The attribute names are replaced with the expression attribute names,
+// and the literals and bind variables are replaced with the expression attribute values.
+// Strings are translated by translate, and the typed expressions, e.g. BeginsWith and IsMissing, by translateExpression.
+func newPutCondition(conditions []condition) (*putCondition, error) {
+	pc := &putCondition{names: map[string]string{}, values: map[string]types.AttributeValue{}}
+	expressions := make([]string, 0, len(conditions))
+	for _, cond := range conditions {
+		var (
+			expression string
+			err        error
+		)
+		switch query := cond.query.(type) {
+		case string:
+			expression, err = pc.translate(query, cond.args)
+		case clause.Expression:
+			if len(cond.args) > 0 {
+				return nil, fmt.Errorf("%w: bind variables with %T in the condition of Create", ErrInvalidStatement, query)
+			}
+			expression, err = pc.translateExpression(query)
+		default:
+			return nil, fmt.Errorf("%w: %T in the condition of Create", ErrDynmgrmAreNotSupported, query)
+		}
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, "("+expression+")")
+	}
+	pc.expression = strings.Join(expressions, " AND ")
+	return pc, nil
+}
+
+// translate translates the PartiQL condition to the condition expression.
+//
+// The condition is limited to the following, and the others, e.g. IS NULL and arithmetic,
+// are rejected with ErrDynmgrmAreNotSupported.
+//
+//   - operands: attribute names and paths, ?, 'strings', numbers, TRUE, FALSE and size(path)
+//   - comparisons: =, <>, !=, <, <=, >, >=, BETWEEN ... AND ... and IN (...) or IN ?
+//   - path IS MISSING and path IS NOT MISSING
+//   - attribute_exists, attribute_not_exists, attribute_type, begins_with and contains
+//   - AND, OR, NOT and parentheses
+func (pc *putCondition) translate(query string, args []interface{}) (string, error) {
+	tokens, err := tokenizeCondition(query)
+	if err != nil {
+		return "", err
+	}
+	p := &conditionParser{pc: pc, query: query, tokens: tokens, args: args}
+	expression, err := p.condition()
+	if err != nil {
+		return "", err
+	}
+	if p.pos < len(p.tokens) {
+		return "", p.unsupported()
+	}
+	if p.arg != len(args) {
+		return "", fmt.Errorf("%w: %d bind variables for %d placeholders in the condition <%s>",
+			ErrInvalidStatement, len(args), p.arg, query)
+	}
+	return expression, nil
+}
+
+// conditionTokenKind is the kind of the tokens of the PartiQL conditions.
+type conditionTokenKind int
+
+const (
+	tokenName conditionTokenKind = iota
+	tokenQuotedName
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+// conditionToken is a token of the PartiQL conditions.
+type conditionToken struct {
+	kind conditionTokenKind
+	// text is the name, the unescaped string, the number or the symbol.
+	text string
+}
+
+// is reports whether the token is the symbol, or the keyword that is case-insensitive.
+func (t conditionToken) is(text string) bool {
+	switch t.kind {
+	case tokenSymbol:
+		return t.text == text
+	case tokenName:
+		return strings.EqualFold(t.text, text)
+	}
+	return false
+}
+
+// tokenizeCondition splits the PartiQL condition into the tokens.
+func tokenizeCondition(query string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	runes := []rune(query)
+	unsupported := func(i int) error {
+		return fmt.Errorf("%w: <%s> in the condition of Create <%s>", ErrDynmgrmAreNotSupported, string(runes[i:]), query)
+	}
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'':
+			var literal strings.Builder
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == '\'' {
+					if j+1 < len(runes) && runes[j+1] == '\'' {
+						literal.WriteRune('\'')
+						j++
+						continue
+					}
+					break
+				}
+				literal.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, unsupported(i)
+			}
+			tokens = append(tokens, conditionToken{kind: tokenString, text: literal.String()})
+			i = j + 1
+		case r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				j++
+			}
+			if j >= len(runes) || j == i+1 {
+				return nil, unsupported(i)
+			}
+			tokens = append(tokens, conditionToken{kind: tokenQuotedName, text: string(runes[i+1 : j])})
+			i = j + 1
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, conditionToken{kind: tokenName, text: string(runes[i:j])})
+			i = j
+		case unicode.IsDigit(r):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			if _, err := strconv.ParseFloat(string(runes[i:j]), 64); err != nil {
+				return nil, unsupported(i)
+			}
+			tokens = append(tokens, conditionToken{kind: tokenNumber, text: string(runes[i:j])})
+			i = j
+		case strings.ContainsRune("<>!", r) && i+1 < len(runes) && runes[i+1] == '=', r == '<' && i+1 < len(runes) && runes[i+1] == '>':
+			tokens = append(tokens, conditionToken{kind: tokenSymbol, text: string(runes[i : i+2])})
+			i += 2
+		case strings.ContainsRune("=<>()[],.?-", r):
+			tokens = append(tokens, conditionToken{kind: tokenSymbol, text: string(r)})
+			i++
+		default:
+			return nil, unsupported(i)
+		}
+	}
+	return tokens, nil
+}
+
+// conditionParser parses the tokens of the PartiQL condition, and writes the condition expression.
+type conditionParser struct {
+	pc     *putCondition
+	query  string
+	tokens []conditionToken
+	pos    int
+	args   []interface{}
+	arg    int
+}
+
+// peek returns the next token, or the zero token at the end.
+func (p *conditionParser) peek() (conditionToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return conditionToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// accept consumes the next token if it is the symbol or the keyword.
+func (p *conditionParser) accept(text string) bool {
+	if t, ok := p.peek(); ok && t.is(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes the next token, which must be the symbol or the keyword.
+func (p *conditionParser) expect(text string) error {
+	if !p.accept(text) {
+		return p.unsupported()
+	}
+	return nil
+}
+
+// unsupported returns the error for the tokens from the current one.
+func (p *conditionParser) unsupported() error {
+	if p.pos >= len(p.tokens) {
+		return fmt.Errorf("%w: the end of the condition of Create <%s>", ErrDynmgrmAreNotSupported, p.query)
+	}
+	return fmt.Errorf("%w: <%s> in the condition of Create <%s>", ErrDynmgrmAreNotSupported, p.tokens[p.pos].text, p.query)
+}
+
+// condition parses the conditions joined by OR.
+func (p *conditionParser) condition() (string, error) {
+	left, err := p.conjunction()
+	if err != nil {
+		return "", err
+	}
+	for p.accept("OR") {
+		right, err := p.conjunction()
+		if err != nil {
+			return "", err
+		}
+		left += " OR " + right
+	}
+	return left, nil
+}
+
+// conjunction parses the conditions joined by AND.
+func (p *conditionParser) conjunction() (string, error) {
+	left, err := p.negation()
+	if err != nil {
+		return "", err
+	}
+	for p.accept("AND") {
+		right, err := p.negation()
+		if err != nil {
+			return "", err
+		}
+		left += " AND " + right
+	}
+	return left, nil
+}
+
+// negation parses the condition that may be negated by NOT.
+func (p *conditionParser) negation() (string, error) {
+	if p.accept("NOT") {
+		operand, err := p.negation()
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + operand, nil
+	}
+	return p.primary()
+}
+
+// primary parses the parenthesized condition, the function, or the comparison.
+func (p *conditionParser) primary() (string, error) {
+	if p.accept("(") {
+		inner, err := p.condition()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect(")"); err != nil {
+			return "", err
+		}
+		return "(" + inner + ")", nil
+	}
+	if function, ok := p.function(); ok {
+		switch function {
+		case "attribute_exists", "attribute_not_exists":
+			return p.call(function, p.path)
+		case "attribute_type", "begins_with":
+			return p.call(function, p.path, p.value)
+		case "contains":
+			return p.call(function, p.path, p.operand)
+		}
+	}
+	start := p.pos
+	left, err := p.operand()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case p.accept("IS"):
+		not := p.accept("NOT")
+		if err := p.expect("MISSING"); err != nil {
+			return "", err
+		}
+		if !p.isPath(start) {
+			return "", p.unsupported()
+		}
+		if not {
+			return "attribute_exists(" + left + ")", nil
+		}
+		return "attribute_not_exists(" + left + ")", nil
+	case p.accept("BETWEEN"):
+		lower, err := p.operand()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("AND"); err != nil {
+			return "", err
+		}
+		upper, err := p.operand()
+		if err != nil {
+			return "", err
+		}
+		return left + " BETWEEN " + lower + " AND " + upper, nil
+	case p.accept("IN"):
+		list, err := p.list()
+		if err != nil {
+			return "", err
+		}
+		return left + " IN " + list, nil
+	}
+	comparator, ok := p.peek()
+	if !ok || comparator.kind != tokenSymbol || !isComparator(comparator.text) {
+		return "", p.unsupported()
+	}
+	p.pos++
+	right, err := p.operand()
+	if err != nil {
+		return "", err
+	}
+	if comparator.text == "!=" {
+		comparator.text = "<>"
+	}
+	return left + " " + comparator.text + " " + right, nil
+}
+
+// function returns the name of the condition function that starts at the current token.
+func (p *conditionParser) function() (string, bool) {
+	t, ok := p.peek()
+	if !ok || t.kind != tokenName || p.pos+1 >= len(p.tokens) || !p.tokens[p.pos+1].is("(") {
+		return "", false
+	}
+	return strings.ToLower(t.text), true
+}
+
+// call parses the arguments of the function, and returns the call of it.
+func (p *conditionParser) call(function string, params ...func() (string, error)) (string, error) {
+	p.pos += 2
+	args := make([]string, 0, len(params))
+	for i, param := range params {
+		if i > 0 {
+			if err := p.expect(","); err != nil {
+				return "", err
+			}
+		}
+		arg, err := param()
+		if err != nil {
+			return "", err
+		}
+		args = append(args, arg)
+	}
+	if err := p.expect(")"); err != nil {
+		return "", err
+	}
+	return function + "(" + strings.Join(args, ", ") + ")", nil
+}
+
+// operand parses the path, the value, or size(path).
+func (p *conditionParser) operand() (string, error) {
+	if function, ok := p.function(); ok {
+		if function != "size" {
+			return "", p.unsupported()
+		}
+		return p.call(function, p.path)
+	}
+	if p.isPath(p.pos) {
+		return p.path()
+	}
+	return p.value()
+}
+
+// isPath reports whether the token at i starts the path to the attribute.
+func (p *conditionParser) isPath(i int) bool {
+	if i >= len(p.tokens) {
+		return false
+	}
+	switch t := p.tokens[i]; t.kind {
+	case tokenQuotedName:
+		return true
+	case tokenName:
+		return !isConditionKeyword(t.text)
+	}
+	return false
+}
+
+// path parses the path to the attribute, and returns it with the placeholders of the names.
+func (p *conditionParser) path() (string, error) {
+	var sb strings.Builder
+	for {
+		if !p.isPath(p.pos) {
+			return "", p.unsupported()
+		}
+		sb.WriteString(p.pc.name(p.tokens[p.pos].text))
+		p.pos++
+		for p.accept("[") {
+			t, ok := p.peek()
+			if !ok || t.kind != tokenNumber || strings.Contains(t.text, ".") {
+				return "", p.unsupported()
+			}
+			p.pos++
+			if err := p.expect("]"); err != nil {
+				return "", err
+			}
+			sb.WriteString("[" + t.text + "]")
+		}
+		if !p.accept(".") {
+			return sb.String(), nil
+		}
+		sb.WriteByte('.')
+	}
+}
+
+// value parses the bind variable or the literal, and returns the placeholder of it.
+func (p *conditionParser) value() (string, error) {
+	t, ok := p.peek()
+	if !ok {
+		return "", p.unsupported()
+	}
+	switch {
+	case t.is("?"):
+		if p.arg >= len(p.args) {
+			return "", fmt.Errorf("%w: missing bind variable for the condition <%s>", ErrInvalidStatement, p.query)
+		}
+		if isList(p.args[p.arg]) {
+			return "", fmt.Errorf("%w: a list bound outside IN in the condition of Create <%s>", ErrDynmgrmAreNotSupported, p.query)
+		}
+		p.pos++
+		p.arg++
+		return p.pc.arg(p.args[p.arg-1])
+	case t.kind == tokenString:
+		p.pos++
+		return p.pc.value(&types.AttributeValueMemberS{Value: t.text}), nil
+	case t.kind == tokenNumber:
+		p.pos++
+		return p.pc.value(&types.AttributeValueMemberN{Value: t.text}), nil
+	case t.is("-") && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].kind == tokenNumber:
+		p.pos += 2
+		return p.pc.value(&types.AttributeValueMemberN{Value: "-" + p.tokens[p.pos-1].text}), nil
+	case t.is("TRUE"), t.is("FALSE"):
+		p.pos++
+		return p.pc.value(&types.AttributeValueMemberBOOL{Value: t.is("TRUE")}), nil
+	}
+	return "", p.unsupported()
+}
+
+// list parses the operands of IN, which are enclosed in parentheses, or bound to ? as a slice.
+func (p *conditionParser) list() (string, error) {
+	if t, ok := p.peek(); ok && t.is("?") && p.arg < len(p.args) && isList(p.args[p.arg]) {
+		p.pos++
+		p.arg++
+		return p.pc.list(p.args[p.arg-1])
+	}
+	if err := p.expect("("); err != nil {
+		return "", err
+	}
+	var operands []string
+	for {
+		operand, err := p.operand()
+		if err != nil {
+			return "", err
+		}
+		operands = append(operands, operand)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return "", err
+	}
+	return "(" + strings.Join(operands, ", ") + ")", nil
+}
+
+// isConditionKeyword reports whether the word is a keyword of the conditions, which cannot be an attribute name unquoted.
+func isConditionKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "AND", "OR", "NOT", "BETWEEN", "IN", "IS", "MISSING", "TRUE", "FALSE", "NULL":
+		return true
+	}
+	return false
+}
+
+// isComparator reports whether the symbol is a comparator of the condition expressions.
+func isComparator(symbol string) bool {
+	switch symbol {
+	case "=", "<>", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// isList reports whether the bind variable is a list for IN, as gorm expands it.
+// Byte slices and the ones that implement driver.Valuer are not lists.
+func isList(arg interface{}) bool {
+	if _, valuer := arg.(driver.Valuer); valuer {
+		return false
+	}
+	rv := reflect.ValueOf(arg)
+	return rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8
+}
+
+// translateExpression translates the typed expression to the condition expression.
+//
+// The expressions of dynmgrm, the comparisons of gorm, clause.And/Or/Not and clause.Expr are supported.
+func (pc *putCondition) translateExpression(expr clause.Expression) (string, error) {
+	unsupported := fmt.Errorf("%w: %T in the condition of Create", ErrDynmgrmAreNotSupported, expr)
+	comparison := func(column interface{}, operator string, value interface{}) (string, error) {
+		if value == nil {
+			return "", unsupported
+		}
+		path, err := pc.column(column)
+		if err != nil {
+			return "", err
+		}
+		if isList(value) {
+			if operator != "=" {
+				return "", unsupported
+			}
+			list, err := pc.list(value)
+			return path + " IN " + list, err
+		}
+		placeholder, err := pc.arg(value)
+		return path + " " + operator + " " + placeholder, err
+	}
+	switch expr := expr.(type) {
+	case conditionFunction:
+		path, err := pc.column(expr.column)
+		if err != nil {
+			return "", err
+		}
+		value, err := pc.arg(expr.value)
+		return expr.name + "(" + path + ", " + value + ")", err
+	case sizeComparison:
+		path, err := pc.column(expr.column)
+		if err != nil {
+			return "", err
+		}
+		return "size(" + path + ") " + expr.operator + " " + pc.value(&types.AttributeValueMemberN{Value: strconv.Itoa(expr.value)}), nil
+	case missing:
+		path, err := pc.column(expr.column)
+		if expr.not {
+			return "attribute_exists(" + path + ")", err
+		}
+		return "attribute_not_exists(" + path + ")", err
+	case between:
+		path, err := pc.column(expr.column)
+		if err != nil {
+			return "", err
+		}
+		lower, err := pc.arg(expr.lower)
+		if err != nil {
+			return "", err
+		}
+		upper, err := pc.arg(expr.upper)
+		return path + " BETWEEN " + lower + " AND " + upper, err
+	case clause.Eq:
+		return comparison(expr.Column, "=", expr.Value)
+	case clause.Neq:
+		return comparison(expr.Column, "<>", expr.Value)
+	case clause.Gt:
+		return comparison(expr.Column, ">", expr.Value)
+	case clause.Gte:
+		return comparison(expr.Column, ">=", expr.Value)
+	case clause.Lt:
+		return comparison(expr.Column, "<", expr.Value)
+	case clause.Lte:
+		return comparison(expr.Column, "<=", expr.Value)
+	case clause.IN:
+		if len(expr.Values) == 0 {
+			return "", unsupported
+		}
+		path, err := pc.column(expr.Column)
+		if err != nil {
+			return "", err
+		}
+		list, err := pc.list(expr.Values)
+		return path + " IN " + list, err
+	case clause.AndConditions:
+		return pc.translateExpressions(expr.Exprs, " AND ")
+	case clause.OrConditions:
+		return pc.translateExpressions(expr.Exprs, " OR ")
+	case clause.NotConditions:
+		// as gorm, each of them is negated if any of them can be negated by itself
+		for _, e := range expr.Exprs {
+			if _, ok := e.(clause.NegationExpressionBuilder); ok {
+				return pc.translateNegations(expr.Exprs)
+			}
+		}
+		inner, err := pc.translateExpressions(expr.Exprs, " AND ")
+		return "NOT (" + inner + ")", err
+	case clause.Expr:
+		return pc.translate(expr.SQL, expr.Vars)
+	}
+	return "", unsupported
+}
+
+// translateExpressions translates the expressions, and joins them with the separator in parentheses.
+func (pc *putCondition) translateExpressions(exprs []clause.Expression, separator string) (string, error) {
+	translated := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		expression, err := pc.translateExpression(expr)
+		if err != nil {
+			return "", err
+		}
+		translated = append(translated, expression)
+	}
+	if len(translated) == 1 {
+		return translated[0], nil
+	}
+	return "(" + strings.Join(translated, separator) + ")", nil
+}
+
+// translateNegations translates the negations of the expressions, and joins them with AND in parentheses.
+func (pc *putCondition) translateNegations(exprs []clause.Expression) (string, error) {
+	translated := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		negated, ok := negation(expr)
+		if !ok {
+			negated = expr
+		}
+		expression, err := pc.translateExpression(negated)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			expression = "NOT (" + expression + ")"
+		}
+		translated = append(translated, expression)
+	}
+	if len(translated) == 1 {
+		return translated[0], nil
+	}
+	return "(" + strings.Join(translated, " AND ") + ")", nil
+}
+
+// negation returns the expression that is the negation of the expression, as NegationBuild of it builds.
+func negation(expr clause.Expression) (clause.Expression, bool) {
+	switch expr := expr.(type) {
+	case missing:
+		return missing{column: expr.column, not: !expr.not}, true
+	case clause.Eq:
+		if isList(expr.Value) {
+			return nil, false
+		}
+		return clause.Neq(expr), true
+	case clause.Neq:
+		return clause.Eq(expr), true
+	case clause.Gt:
+		return clause.Lte(expr), true
+	case clause.Gte:
+		return clause.Lt(expr), true
+	case clause.Lt:
+		return clause.Gte(expr), true
+	case clause.Lte:
+		return clause.Gt(expr), true
+	}
+	return nil, false
+}
+
+// column returns the column of the typed expression with the placeholders of the names.
+// The column may be the path to the nested attribute.
+func (pc *putCondition) column(column interface{}) (string, error) {
+	var name string
+	switch column := column.(type) {
+	case string:
+		name = column
+	case clause.Column:
+		if column.Raw || column.Table != "" {
+			return "", fmt.Errorf("%w: %v in the condition of Create", ErrDynmgrmAreNotSupported, column)
+		}
+		name = column.Name
+	default:
+		return "", fmt.Errorf("%w: %T in the condition of Create", ErrDynmgrmAreNotSupported, column)
+	}
+	if !strings.ContainsAny(name, `.["`) {
+		if !reInvalidColumnName.MatchString(name) {
+			return "", ErrInvalidColumnName
+		}
+		return pc.name(name), nil
+	}
+	segments, err := parsePath(name)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for i, segment := range segments {
+		if segment.name == "" {
+			sb.WriteString("[" + strconv.Itoa(segment.index) + "]")
+			continue
+		}
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(pc.name(segment.name))
+	}
+	return sb.String(), nil
+}
+
+// list returns the placeholders of the elements of the slice in parentheses.
+func (pc *putCondition) list(values interface{}) (string, error) {
+	rv := reflect.ValueOf(values)
+	if rv.Len() == 0 {
+		return "", fmt.Errorf("%w: an empty list in the condition of Create", ErrDynmgrmAreNotSupported)
+	}
+	placeholders := make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		placeholder, err := pc.arg(rv.Index(i).Interface())
+		if err != nil {
+			return "", err
+		}
+		placeholders = append(placeholders, placeholder)
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")", nil
+}
+
+// arg returns the placeholder of the bind variable.
+func (pc *putCondition) arg(arg interface{}) (string, error) {
+	av, err := godynamo.ToAttributeValue(arg)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling bind variable of the condition: %w", err)
+	}
+	return pc.value(av), nil
+}
+
+// value returns the placeholder of the expression attribute value.
+func (pc *putCondition) value(av types.AttributeValue) string {
+	placeholder := fmt.Sprintf(":v%d", len(pc.values))
+	pc.values[placeholder] = av
+	return placeholder
+}
+
+// name returns the placeholder of the expression attribute name.
+func (pc *putCondition) name(name string) string {
+	for placeholder, n := range pc.names {
+		if n == name {
+			return placeholder
+		}
+	}
+	placeholder := fmt.Sprintf("#n%d", len(pc.names))
+	pc.names[placeholder] = name
+	return placeholder
+}
@@ -72,10 +72,14 @@ type secondaryIndexProperty struct {
 }
 
 type dynmgrmTag struct {
-	PK            bool
-	SK            bool
-	IndexProperty []secondaryIndexProperty
-	NonProjective []string
+	PK              bool
+	SK              bool
+	IndexProperty   []secondaryIndexProperty
+	NonProjective   []string
+	ZeroValuePolicy ZeroValuePolicy
+	// ZeroValuePolicySpecified is whether the zero-value tag is specified, even if its value is empty
+	ZeroValuePolicySpecified bool
+	Version                  bool
 }
 
 func newDynmgrmTag(tag reflect.StructTag) dynmgrmTag {
@@ -122,6 +126,12 @@ func newDynmgrmTag(tag reflect.StructTag) dynmgrmTag {
 			for _, np := range strings.Split(npl, ",") {
 				res.NonProjective = append(res.NonProjective, np)
 			}
+		case "version":
+			res.Version = true
+		case "zero-value":
+			// validated when the policy is applied, so that unknown ones are reported
+			res.ZeroValuePolicy = ZeroValuePolicy(strings.Join(kv[1:], ":"))
+			res.ZeroValuePolicySpecified = true
 		}
 	}
 	return res
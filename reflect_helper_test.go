@@ -256,6 +256,9 @@ func Test_newDynmgrmTag(t *testing.T) {
 		G string `dynmgrm:"pk;sk"`
 		H string `dynmgrm:"sk;pk"`
 		I string `dynmgrm:""`
+		J string `dynmgrm:"zero-value:always"`
+		K string `dynmgrm:"zero-value:unknown"`
+		L int    `dynmgrm:"version"`
 	}
 	rt := reflect.TypeOf(A{})
 	type args struct {
@@ -356,6 +359,32 @@ func Test_newDynmgrmTag(t *testing.T) {
 			},
 			want: dynmgrmTag{},
 		},
+		"happy_path/zero-value": {
+			args: args{
+				tag: rt.Field(9).Tag,
+			},
+			want: dynmgrmTag{
+				ZeroValuePolicy:          ZeroValuePolicyAlways,
+				ZeroValuePolicySpecified: true,
+			},
+		},
+		"unhappy_path/unknown_zero-value": {
+			args: args{
+				tag: rt.Field(10).Tag,
+			},
+			want: dynmgrmTag{
+				ZeroValuePolicy:          "unknown",
+				ZeroValuePolicySpecified: true,
+			},
+		},
+		"happy_path/version": {
+			args: args{
+				tag: rt.Field(11).Tag,
+			},
+			want: dynmgrmTag{
+				Version: true,
+			},
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
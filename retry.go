@@ -0,0 +1,405 @@
+package dynmgrm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// compatibility check
+var _ DynamoDBAPI = (*retryClient)(nil)
+
+var (
+	// reInsert matches INSERT statements, which are not idempotent.
+	reInsert = regexp.MustCompile(`(?i)^\s*INSERT\s`)
+	// reUpdate matches UPDATE statements.
+	reUpdate = regexp.MustCompile(`(?i)^\s*UPDATE\s`)
+	// reReturning matches the statements with RETURNING, which return the different item when executed again.
+	reReturning = regexp.MustCompile(`(?i)\sRETURNING\s`)
+	// reUpdateClause matches the clauses of UPDATE statements.
+	reUpdateClause = regexp.MustCompile(`(?i)\s(SET|REMOVE|WHERE)\s`)
+	// reAssignedValue matches the values that are assigned as they are, regardless of the current item.
+	reAssignedValue = regexp.MustCompile(`(?i)^(\?|('')+|[-+]?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?|true|false|null)$`)
+)
+
+// RetryPolicy is the policy for retrying the requests that DynamoDB throttles or fails transiently.
+//
+// ProvisionedThroughputExceeded, Throttling and RequestLimitExceeded are always retried, since DynamoDB has not applied the requests.
+// InternalServerError is retried only for the idempotent statements, since the others may have been applied.
+// INSERT, the statements with RETURNING, and UPDATE that assigns a value computed from the item,
+// e.g. Increment, Decrement, ListAppend, ListPrepend, SetAdd and SetDelete, are not idempotent.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	//
+	// Default: 5
+	MaxAttempts int
+	// InitialInterval is the upper bound of the first backoff.
+	// The upper bound doubles on each retry, and the backoff is chosen randomly below it.
+	//
+	// Default: 50ms
+	InitialInterval time.Duration
+	// MaxInterval is the maximum upper bound of the backoff.
+	//
+	// Default: 5s
+	MaxInterval time.Duration
+	// MaxElapsedTime is the maximum time spent on a request, including the retries.
+	//
+	// Default: 30s
+	MaxElapsedTime time.Duration
+	// RetryInserts is whether INSERT statements are retried on InternalServerError.
+	// An INSERT that has been applied fails with the duplicate item error when retried.
+	// The other statements that are not idempotent are never retried on InternalServerError.
+	//
+	// Default: false
+	RetryInserts bool
+}
+
+// WithRetryPolicy sets the policy for retrying the requests that DynamoDB throttles or fails transiently.
+// The zero values of the policy are replaced with the defaults.
+// The retryer of the SDK is disabled on all DynamoDB clients, including the one given by WithDynamoDBClient
+// and the ones that the driver builds, so that the requests are retried only by the policy.
+//
+// Default: the requests are not retried by dynmgrm
+func WithRetryPolicy(policy RetryPolicy) func(*config) {
+	return func(config *config) {
+		config.retryPolicy = &policy
+	}
+}
+
+// withDefaults returns the policy whose zero values are replaced with the defaults.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 5
+	}
+	if p.InitialInterval <= 0 {
+		p.InitialInterval = 50 * time.Millisecond
+	}
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = 5 * time.Second
+	}
+	if p.MaxElapsedTime <= 0 {
+		p.MaxElapsedTime = 30 * time.Second
+	}
+	return p
+}
+
+// sleep waits for the duration, or until the context is done.
+// It is a variable for testing.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff returns the jittered backoff before the n-th retry.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	upper := p.MaxInterval
+	if n < 32 {
+		upper = min(upper, p.InitialInterval<<n)
+	}
+	if upper <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(upper)))
+}
+
+// do calls fn until it succeeds, or returns an error that is not retryable.
+// retryable reports whether the error returned by fn is retryable.
+func (p RetryPolicy) do(ctx context.Context, retryable func(err error) bool, fn func() error) error {
+	p = p.withDefaults()
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+			return err
+		}
+		d := p.backoff(attempt - 1)
+		if time.Since(start)+d > p.MaxElapsedTime {
+			return err
+		}
+		if sleepErr := sleep(ctx, d); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+// retryableFor returns the function that reports whether the error of the statement is retryable.
+func (p RetryPolicy) retryableFor(idempotent bool) func(err error) bool {
+	return func(err error) bool {
+		var apiErr smithy.APIError
+		if !errors.As(err, &apiErr) {
+			return false
+		}
+		return isRetryableCode(apiErr.ErrorCode(), idempotent)
+	}
+}
+
+// isIdempotent reports whether the statement is retried as an idempotent one by the policy.
+func (p RetryPolicy) isIdempotent(statement string) bool {
+	if p.RetryInserts && reInsert.MatchString(statement) {
+		return true
+	}
+	return isIdempotent(statement)
+}
+
+// isRetryableCode reports whether the error code of DynamoDB is retryable.
+// InternalServerError is retryable only if the request is idempotent.
+func isRetryableCode(code string, idempotent bool) bool {
+	switch code {
+	case "ProvisionedThroughputExceededException", "ProvisionedThroughputExceeded",
+		"ThrottlingException", "ThrottlingError", "RequestLimitExceeded":
+		return true
+	case "InternalServerError":
+		return idempotent
+	}
+	return false
+}
+
+// isIdempotent reports whether the statement can be executed more than once with the same result.
+//
+// UPDATE is idempotent only if every SET assigns a placeholder or a literal.
+func isIdempotent(statement string) bool {
+	statement = maskLiterals(statement)
+	if reInsert.MatchString(statement) || reReturning.MatchString(statement) {
+		return false
+	}
+	if !reUpdate.MatchString(statement) {
+		return true
+	}
+	clauses := reUpdateClause.FindAllStringSubmatchIndex(statement, -1)
+	for i, loc := range clauses {
+		keyword := strings.ToUpper(statement[loc[2]:loc[3]])
+		if keyword == "WHERE" {
+			break
+		}
+		if keyword != "SET" {
+			continue
+		}
+		end := len(statement)
+		if i+1 < len(clauses) {
+			end = clauses[i+1][0]
+		}
+		_, value, ok := strings.Cut(statement[loc[1]:end], "=")
+		if !ok || !reAssignedValue.MatchString(strings.TrimSpace(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// maskLiterals returns the statement whose string literals and quoted names are emptied,
+// so that their contents are not taken as keywords.
+func maskLiterals(statement string) string {
+	masked := strings.Builder{}
+	masked.Grow(len(statement))
+	var quote rune
+	for _, r := range statement {
+		switch {
+		case quote == 0 && (r == '\'' || r == '"'):
+			quote = r
+		case r == quote:
+			quote = 0
+		case quote != 0:
+			continue
+		}
+		masked.WriteRune(r)
+	}
+	return masked.String()
+}
+
+// retryClient is a DynamoDBAPI that retries the requests with RetryPolicy.
+//
+// The retryer of the SDK is disabled on every request, so that the requests are retried only by RetryPolicy.
+type retryClient struct {
+	DynamoDBAPI
+	policy RetryPolicy
+}
+
+// withoutRetryer returns the options that disable the retryer of the SDK in addition to the optFns.
+func withoutRetryer(optFns []func(*dynamodb.Options)) []func(*dynamodb.Options) {
+	return append(slices.Clone(optFns), func(o *dynamodb.Options) {
+		o.Retryer = aws.NopRetryer{}
+	})
+}
+
+// ExecuteStatement implements DynamoDBAPI.
+func (c *retryClient) ExecuteStatement(
+	ctx context.Context,
+	params *dynamodb.ExecuteStatementInput,
+	optFns ...func(*dynamodb.Options),
+) (output *dynamodb.ExecuteStatementOutput, err error) {
+	optFns = withoutRetryer(optFns)
+	retryable := c.policy.retryableFor(c.policy.isIdempotent(aws.ToString(params.Statement)))
+	err = c.policy.do(ctx, retryable, func() error {
+		output, err = c.DynamoDBAPI.ExecuteStatement(ctx, params, optFns...)
+		return err
+	})
+	return output, err
+}
+
+// PutItem implements DynamoDBAPI.
+// PutItem without conditions is idempotent, since it overwrites the item.
+// The one with conditions is retried as INSERT.
+func (c *retryClient) PutItem(
+	ctx context.Context,
+	params *dynamodb.PutItemInput,
+	optFns ...func(*dynamodb.Options),
+) (output *dynamodb.PutItemOutput, err error) {
+	optFns = withoutRetryer(optFns)
+	idempotent := params.ConditionExpression == nil || c.policy.RetryInserts
+	err = c.policy.do(ctx, c.policy.retryableFor(idempotent), func() error {
+		output, err = c.DynamoDBAPI.PutItem(ctx, params, optFns...)
+		return err
+	})
+	return output, err
+}
+
+// BatchExecuteStatement implements DynamoDBAPI.
+//
+// The statements that fail with the retryable errors are retried in the next batch,
+// and their responses are merged in the order of the statements.
+func (c *retryClient) BatchExecuteStatement(
+	ctx context.Context,
+	params *dynamodb.BatchExecuteStatementInput,
+	optFns ...func(*dynamodb.Options),
+) (*dynamodb.BatchExecuteStatementOutput, error) {
+	optFns = withoutRetryer(optFns)
+	idempotent := true
+	for _, statement := range params.Statements {
+		idempotent = idempotent && c.policy.isIdempotent(aws.ToString(statement.Statement))
+	}
+	var output *dynamodb.BatchExecuteStatementOutput
+	pending := make([]int, len(params.Statements))
+	for i := range pending {
+		pending[i] = i
+	}
+	input := *params
+	retryableAPIError := c.policy.retryableFor(idempotent)
+	retryable := func(err error) bool {
+		return errors.Is(err, errBatchStatementsRetrying) || retryableAPIError(err)
+	}
+	err := c.policy.do(ctx, retryable, func() error {
+		input.Statements = make([]types.BatchStatementRequest, 0, len(pending))
+		for _, i := range pending {
+			input.Statements = append(input.Statements, params.Statements[i])
+		}
+		out, err := c.DynamoDBAPI.BatchExecuteStatement(ctx, &input, optFns...)
+		if err != nil {
+			return err
+		}
+		if output == nil {
+			output = out
+		} else {
+			for j, i := range pending {
+				if j < len(out.Responses) {
+					output.Responses[i] = out.Responses[j]
+				}
+			}
+			output.ConsumedCapacity = append(output.ConsumedCapacity, out.ConsumedCapacity...)
+		}
+		var retrying []int
+		for _, i := range pending {
+			if i >= len(output.Responses) || output.Responses[i].Error == nil {
+				continue
+			}
+			statementIdempotent := c.policy.isIdempotent(aws.ToString(params.Statements[i].Statement))
+			if isRetryableCode(string(output.Responses[i].Error.Code), statementIdempotent) {
+				retrying = append(retrying, i)
+			}
+		}
+		pending = retrying
+		if len(pending) > 0 {
+			return errBatchStatementsRetrying
+		}
+		return nil
+	})
+	if output != nil {
+		// the statements that have not succeeded are left with the errors of their last responses
+		return output, nil
+	}
+	return nil, err
+}
+
+// errBatchStatementsRetrying is returned inside BatchExecuteStatement when some statements are to be retried.
+var errBatchStatementsRetrying = errors.New("some statements are to be retried")
+
+// ExecuteTransaction implements DynamoDBAPI.
+//
+// Transactions canceled only by throttling are retried, since DynamoDB has applied none of the statements.
+// Transactions with ClientRequestToken are idempotent.
+func (c *retryClient) ExecuteTransaction(
+	ctx context.Context,
+	params *dynamodb.ExecuteTransactionInput,
+	optFns ...func(*dynamodb.Options),
+) (output *dynamodb.ExecuteTransactionOutput, err error) {
+	optFns = withoutRetryer(optFns)
+	idempotent := params.ClientRequestToken != nil
+	if !idempotent {
+		idempotent = true
+		for _, statement := range params.TransactStatements {
+			idempotent = idempotent && c.policy.isIdempotent(aws.ToString(statement.Statement))
+		}
+	}
+	retryableAPIError := c.policy.retryableFor(idempotent)
+	retryable := func(err error) bool {
+		var tce *types.TransactionCanceledException
+		if !errors.As(err, &tce) {
+			return retryableAPIError(err)
+		}
+		throttled := false
+		for _, reason := range tce.CancellationReasons {
+			switch code := aws.ToString(reason.Code); code {
+			case "", "None":
+			default:
+				if !isRetryableCode(code, false) {
+					return false
+				}
+				throttled = true
+			}
+		}
+		return throttled
+	}
+	err = c.policy.do(ctx, retryable, func() error {
+		output, err = c.DynamoDBAPI.ExecuteTransaction(ctx, params, optFns...)
+		return err
+	})
+	return output, err
+}
+
+// ExecContext executes the statement with the retry policy.
+func (p *connPool) ExecContext(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
+	if p.retryPolicy == nil {
+		return p.ConnPool.ExecContext(ctx, query, args...)
+	}
+	err = p.retryPolicy.do(ctx, p.retryPolicy.retryableFor(p.retryPolicy.isIdempotent(query)), func() error {
+		result, err = p.ConnPool.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// QueryContext issues the query with the retry policy.
+func (p *connPool) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	if p.retryPolicy == nil {
+		return p.ConnPool.QueryContext(ctx, query, args...)
+	}
+	err = p.retryPolicy.do(ctx, p.retryPolicy.retryableFor(p.retryPolicy.isIdempotent(query)), func() error {
+		rows, err = p.ConnPool.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
@@ -0,0 +1,402 @@
+package dynmgrm
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/miyamo2/dynmgrm/internal/mocks"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+// stubSleep replaces sleep with the one that returns without waiting.
+func stubSleep(t *testing.T) {
+	t.Helper()
+	original := sleep
+	sleep = func(_ context.Context, _ time.Duration) error {
+		return nil
+	}
+	t.Cleanup(func() {
+		sleep = original
+	})
+}
+
+func TestRetryPolicy_do(t *testing.T) {
+	type want struct {
+		attempts int
+		err      error
+	}
+	type test struct {
+		policy    RetryPolicy
+		statement string
+		errs      []error
+		want      want
+	}
+	const (
+		selectSQL = `SELECT * FROM "test_items" WHERE "pk" = ?`
+		insertSQL = `INSERT INTO "test_items" VALUE {'pk' : ?, 'sk' : ?, 'name' : ?}`
+		addSQL    = `UPDATE "test_items" SET "count"="count" + ? WHERE "pk" = ? AND "sk" = ?`
+	)
+	errThrottling := &types.ProvisionedThroughputExceededException{Message: aws.String("throttled")}
+	errLimit := &types.RequestLimitExceeded{Message: aws.String("limit exceeded")}
+	errInternalServer := &types.InternalServerError{Message: aws.String("internal server error")}
+	errValidation := errors.New("validation error")
+	tests := map[string]test{
+		"happy_path/no_error": {
+			statement: selectSQL,
+			want:      want{attempts: 1},
+		},
+		"happy_path/throttled_then_succeeded": {
+			statement: insertSQL,
+			errs:      []error{errThrottling, errLimit},
+			want:      want{attempts: 3},
+		},
+		"happy_path/internal_server_error_of_idempotent": {
+			statement: selectSQL,
+			errs:      []error{errInternalServer},
+			want:      want{attempts: 2},
+		},
+		"happy_path/internal_server_error_of_insert_with_retry_inserts": {
+			policy:    RetryPolicy{RetryInserts: true},
+			statement: insertSQL,
+			errs:      []error{errInternalServer},
+			want:      want{attempts: 2},
+		},
+		"happy_path/throttled_increment": {
+			statement: addSQL,
+			errs:      []error{errThrottling},
+			want:      want{attempts: 2},
+		},
+		"unhappy_path/internal_server_error_of_insert": {
+			statement: insertSQL,
+			errs:      []error{errInternalServer},
+			want:      want{attempts: 1, err: errInternalServer},
+		},
+		"unhappy_path/internal_server_error_of_increment_with_retry_inserts": {
+			policy:    RetryPolicy{RetryInserts: true},
+			statement: addSQL,
+			errs:      []error{errInternalServer},
+			want:      want{attempts: 1, err: errInternalServer},
+		},
+		"unhappy_path/not_retryable": {
+			statement: selectSQL,
+			errs:      []error{errValidation},
+			want:      want{attempts: 1, err: errValidation},
+		},
+		"unhappy_path/max_attempts": {
+			policy:    RetryPolicy{MaxAttempts: 2},
+			statement: selectSQL,
+			errs:      []error{errThrottling, errThrottling, errThrottling},
+			want:      want{attempts: 2, err: errThrottling},
+		},
+		"unhappy_path/max_elapsed_time": {
+			policy:    RetryPolicy{InitialInterval: time.Hour, MaxInterval: time.Hour, MaxElapsedTime: time.Nanosecond},
+			statement: selectSQL,
+			errs:      []error{errThrottling, errThrottling},
+			want:      want{attempts: 1, err: errThrottling},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			stubSleep(t)
+			attempts := 0
+			err := tt.policy.do(context.Background(), tt.policy.retryableFor(tt.policy.isIdempotent(tt.statement)), func() error {
+				attempts++
+				if attempts <= len(tt.errs) {
+					return tt.errs[attempts-1]
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.want.err) {
+				t.Fatalf("do() error = %v, want %v", err, tt.want.err)
+			}
+			if diff := cmp.Diff(tt.want.attempts, attempts); diff != "" {
+				t.Errorf("attempts mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	tests := map[string]struct {
+		statement string
+		want      bool
+	}{
+		"select": {
+			statement: `SELECT * FROM "test_items" WHERE "pk" = ?`,
+			want:      true,
+		},
+		"delete": {
+			statement: `DELETE FROM "test_items" WHERE "pk" = ? AND "sk" = ?`,
+			want:      true,
+		},
+		"condition_check": {
+			statement: `EXISTS(SELECT * FROM "test_items" WHERE "pk" = ? AND "status" = ?)`,
+			want:      true,
+		},
+		"update/placeholders": {
+			statement: `UPDATE "test_items" SET "name"=? SET "count"=? REMOVE "tags" WHERE "pk" = ? AND "sk" = ?`,
+			want:      true,
+		},
+		"update/literals": {
+			statement: `UPDATE "test_items" SET "name"='Item1 SET "count"="count" + 1' SET "count"=-1.5 WHERE "count" = "count" + ?`,
+			want:      true,
+		},
+		"insert": {
+			statement: ` insert INTO "test_items" VALUE {'pk' : ?}`,
+			want:      false,
+		},
+		"update/increment": {
+			statement: `UPDATE "test_items" SET "name"=? SET "count"="count" + ? WHERE "pk" = ?`,
+			want:      false,
+		},
+		"update/version": {
+			statement: `UPDATE "test_items" SET "name"=? SET "version"="version" + 1 WHERE "version" = ? AND "pk" = ?`,
+			want:      false,
+		},
+		"update/expression": {
+			statement: `UPDATE "test_items" SET "amount"=amount - ? WHERE "pk" = ?`,
+			want:      false,
+		},
+		"update/list_append": {
+			statement: `UPDATE "test_items" SET "list"=list_append("list", ?) WHERE "pk" = ?`,
+			want:      false,
+		},
+		"update/list_prepend": {
+			statement: `UPDATE "test_items" SET "list"=list_append(?, "list") WHERE "pk" = ?`,
+			want:      false,
+		},
+		"update/set_add": {
+			statement: `UPDATE "test_items" SET "tags"=set_add("tags", ?) WHERE "pk" = ?`,
+			want:      false,
+		},
+		"update/set_delete": {
+			statement: `UPDATE "test_items" SET "tags"=set_delete("tags", ?) WHERE "pk" = ?`,
+			want:      false,
+		},
+		"update/returning": {
+			statement: `UPDATE "test_items" SET "name"=? WHERE "pk" = ? RETURNING ALL OLD *`,
+			want:      false,
+		},
+		"delete/returning": {
+			statement: `DELETE FROM "test_items" WHERE "pk" = ? RETURNING ALL OLD *`,
+			want:      false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, isIdempotent(tt.statement)); diff != "" {
+				t.Errorf("isIdempotent() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := RetryPolicy{InitialInterval: 10 * time.Millisecond, MaxInterval: 50 * time.Millisecond}
+	uppers := []time.Duration{10, 20, 40, 50, 50, 50}
+	for n, upper := range uppers {
+		for i := 0; i < 100; i++ {
+			if d := policy.backoff(n); d < 0 || d >= upper*time.Millisecond {
+				t.Fatalf("backoff(%d) = %v, want in [0, %v)", n, d, upper*time.Millisecond)
+			}
+		}
+	}
+}
+
+func TestConnPool_ExecContext(t *testing.T) {
+	type test struct {
+		operation func(db *gorm.DB) *gorm.DB
+		err       error
+		want      int
+	}
+	errThrottling := &types.RequestLimitExceeded{Message: aws.String("throttled")}
+	errInternalServer := &types.InternalServerError{Message: aws.String("internal server error")}
+	insert := func(db *gorm.DB) *gorm.DB {
+		return db.Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+	}
+	update := func(db *gorm.DB) *gorm.DB {
+		return db.Model(&testItem{PK: "Partition1", SK: 1}).Update("name", "Item1")
+	}
+	increment := func(db *gorm.DB) *gorm.DB {
+		return db.Model(&testItem{PK: "Partition1", SK: 1}).Update("name", Increment(1))
+	}
+	tests := map[string]test{
+		"insert/throttled": {
+			operation: insert,
+			err:       errThrottling,
+			want:      3,
+		},
+		"insert/internal_server_error": {
+			operation: insert,
+			err:       errInternalServer,
+			want:      1,
+		},
+		"update/internal_server_error": {
+			operation: update,
+			err:       errInternalServer,
+			want:      3,
+		},
+		"increment/throttled": {
+			operation: increment,
+			err:       errThrottling,
+			want:      3,
+		},
+		"increment/internal_server_error": {
+			operation: increment,
+			err:       errInternalServer,
+			want:      1,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			stubSleep(t)
+			pool := &fakeConnPool{rowsAffected: 1, err: tt.err}
+			db := openTestDB(t, pool, mocks.NewMockDynamoDBAPI(gomock.NewController(t)), nil)
+			db.ConnPool.(*connPool).retryPolicy = &RetryPolicy{MaxAttempts: 3}
+
+			if err := tt.operation(db).Error; !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if diff := cmp.Diff(tt.want, len(pool.execs)); diff != "" {
+				t.Errorf("executions mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRetryClient_BatchExecuteStatement(t *testing.T) {
+	stubSleep(t)
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockDynamoDBAPI(ctrl)
+	throttled := &types.BatchStatementError{Code: types.BatchStatementErrorCodeEnumThrottlingError}
+	internal := &types.BatchStatementError{Code: types.BatchStatementErrorCodeEnumInternalServerError}
+	var batches [][]string
+	outputs := []*dynamodb.BatchExecuteStatementOutput{
+		{Responses: []types.BatchStatementResponse{{}, {Error: throttled}, {Error: internal}, {Error: throttled}}},
+		{Responses: []types.BatchStatementResponse{{Error: throttled}, {}}},
+		{Responses: []types.BatchStatementResponse{{}}},
+	}
+	client.EXPECT().BatchExecuteStatement(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *dynamodb.BatchExecuteStatementInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchExecuteStatementOutput, error) {
+			var batch []string
+			for _, statement := range in.Statements {
+				batch = append(batch, aws.ToString(statement.Statement))
+			}
+			batches = append(batches, batch)
+			return outputs[len(batches)-1], nil
+		}).Times(3)
+
+	sut := &retryClient{DynamoDBAPI: client}
+	output, err := sut.BatchExecuteStatement(context.Background(), &dynamodb.BatchExecuteStatementInput{
+		Statements: []types.BatchStatementRequest{
+			{Statement: aws.String("INSERT 0")},
+			{Statement: aws.String("INSERT 1")},
+			{Statement: aws.String("INSERT 2")},
+			{Statement: aws.String("INSERT 3")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BatchExecuteStatement() error = %v", err)
+	}
+	wantBatches := [][]string{
+		{"INSERT 0", "INSERT 1", "INSERT 2", "INSERT 3"},
+		{"INSERT 1", "INSERT 3"},
+		{"INSERT 1"},
+	}
+	if diff := cmp.Diff(wantBatches, batches); diff != "" {
+		t.Errorf("batches mismatch (-want +got):\n%s", diff)
+	}
+	var codes []types.BatchStatementErrorCodeEnum
+	for _, response := range output.Responses {
+		if response.Error == nil {
+			codes = append(codes, "")
+			continue
+		}
+		codes = append(codes, response.Error.Code)
+	}
+	wantCodes := []types.BatchStatementErrorCodeEnum{"", "", types.BatchStatementErrorCodeEnumInternalServerError, ""}
+	if diff := cmp.Diff(wantCodes, codes); diff != "" {
+		t.Errorf("codes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRetryClient_ExecuteTransaction(t *testing.T) {
+	type test struct {
+		err  error
+		want int
+	}
+	canceled := func(codes ...string) error {
+		reasons := make([]types.CancellationReason, 0, len(codes))
+		for _, code := range codes {
+			reasons = append(reasons, types.CancellationReason{Code: aws.String(code)})
+		}
+		return &types.TransactionCanceledException{CancellationReasons: reasons}
+	}
+	tests := map[string]test{
+		"canceled_by_throttling": {
+			err:  canceled("None", "ThrottlingError"),
+			want: 3,
+		},
+		"canceled_by_condition": {
+			err:  canceled("ThrottlingError", "ConditionalCheckFailed"),
+			want: 1,
+		},
+		"canceled_by_conflict": {
+			err:  canceled("TransactionConflict", "None"),
+			want: 1,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			stubSleep(t)
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockDynamoDBAPI(ctrl)
+			client.EXPECT().ExecuteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, tt.err).Times(tt.want)
+
+			sut := &retryClient{DynamoDBAPI: client, policy: RetryPolicy{MaxAttempts: 3}}
+			_, err := sut.ExecuteTransaction(context.Background(), &dynamodb.ExecuteTransactionInput{
+				TransactStatements: []types.ParameterizedStatement{{Statement: aws.String("INSERT 0")}},
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ExecuteTransaction() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRetryClient_disablesRetryer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockDynamoDBAPI(ctrl)
+	var got dynamodb.Options
+	client.EXPECT().ExecuteStatement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *dynamodb.ExecuteStatementInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ExecuteStatementOutput, error) {
+			got = dynamodb.Options{Retryer: retry.NewStandard()}
+			for _, fn := range optFns {
+				fn(&got)
+			}
+			return &dynamodb.ExecuteStatementOutput{}, nil
+		}).Times(1)
+
+	sut := &retryClient{DynamoDBAPI: client, policy: RetryPolicy{MaxAttempts: 3}}
+	_, err := sut.ExecuteStatement(context.Background(), &dynamodb.ExecuteStatementInput{
+		Statement: aws.String(`SELECT * FROM "test_items"`),
+	}, func(o *dynamodb.Options) {
+		o.Region = "ap-northeast-1"
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStatement() error = %v", err)
+	}
+	if _, ok := got.Retryer.(aws.NopRetryer); !ok {
+		t.Errorf("Retryer = %T, want aws.NopRetryer", got.Retryer)
+	}
+	if diff := cmp.Diff("ap-northeast-1", got.Region); diff != "" {
+		t.Errorf("Region mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,77 @@
+package dynmgrm
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrReturningInTransaction occurs when RETURNING is used in a transaction begun by the user,
+// since DynamoDB does not return items from transactions.
+var ErrReturningInTransaction = errors.New("RETURNING is not supported in transactions")
+
+// compatibility check
+var _ clause.Interface = (*returningExpression)(nil)
+
+// ReturningMode is the item that UPDATE/DELETE statements return.
+type ReturningMode string
+
+const (
+	// ReturningAllOld returns the whole item before the statement.
+	ReturningAllOld ReturningMode = "ALL OLD"
+	// ReturningModifiedOld returns the updated attributes before the statement.
+	ReturningModifiedOld ReturningMode = "MODIFIED OLD"
+	// ReturningAllNew returns the whole item after the statement.
+	ReturningAllNew ReturningMode = "ALL NEW"
+	// ReturningModifiedNew returns the updated attributes after the statement.
+	ReturningModifiedNew ReturningMode = "MODIFIED NEW"
+)
+
+// returningExpression is a clause.Interface that returns the updated or deleted item.
+type returningExpression struct {
+	mode ReturningMode
+}
+
+// Name returns the name of the clause.
+func (r returningExpression) Name() string {
+	return "RETURNING"
+}
+
+// Build builds the RETURNING clause.
+func (r returningExpression) Build(builder clause.Builder) {
+	builder.WriteString(string(r.mode))
+	builder.WriteString(" *")
+}
+
+// MergeClause merges the returningExpression into the clause.
+func (r returningExpression) MergeClause(clause *clause.Clause) {
+	clause.Expression = r
+}
+
+// Returning scans the item returned by the UPDATE/DELETE statement into the model.
+//
+// DELETE statements support ReturningAllOld only.
+func Returning(mode ReturningMode) returningExpression {
+	return returningExpression{mode: mode}
+}
+
+// buildReturningClause builds the RETURNING clause.
+// clause.Returning returns ALL NEW on UPDATE, and ALL OLD on DELETE.
+func buildReturningClause(c clause.Clause, builder clause.Builder) {
+	var mode ReturningMode
+	switch expr := c.Expression.(type) {
+	case returningExpression:
+		mode = expr.mode
+	case clause.Returning:
+		mode = ReturningAllNew
+		if stmt, ok := builder.(*gorm.Statement); ok {
+			if _, ok := stmt.Clauses["DELETE"]; ok {
+				mode = ReturningAllOld
+			}
+		}
+	default:
+		return
+	}
+	builder.WriteString("RETURNING ")
+	returningExpression{mode: mode}.Build(builder)
+}
@@ -0,0 +1,22 @@
+package dynmgrm_test
+
+import (
+	"github.com/miyamo2/dynmgrm"
+	"gorm.io/gorm"
+)
+
+func ExampleReturning() {
+	db, err := gorm.Open(dynmgrm.New(), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+
+	item := TestTable{PK: "Partition1", SK: 1}
+	db.Model(&item).
+		Clauses(dynmgrm.Returning(dynmgrm.ReturningAllNew)).
+		Update("gsi_key", "GSI1")
+
+	deleted := TestTable{PK: "Partition1", SK: 2}
+	db.Clauses(dynmgrm.Returning(dynmgrm.ReturningAllOld)).
+		Delete(&deleted)
+}
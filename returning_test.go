@@ -0,0 +1,169 @@
+package dynmgrm
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"testing"
+	"time"
+)
+
+type returningItem struct {
+	PK        string `gorm:"primaryKey"`
+	SK        int    `gorm:"primaryKey"`
+	Name      string
+	Count     int
+	UpdatedAt time.Time
+}
+
+func TestReturning(t *testing.T) {
+	type test struct {
+		operation func(db *gorm.DB) *gorm.DB
+		want      string
+	}
+	tests := map[string]test{
+		"happy_path/update_all_new": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Model(&testItem{PK: "Partition1", SK: 1}).Clauses(Returning(ReturningAllNew)).Update("name", "Item1")
+			},
+			want: `UPDATE "test_items" SET "name"=? WHERE "pk" = ? AND "sk" = ? RETURNING ALL NEW *`,
+		},
+		"happy_path/update_modified_old": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Model(&testItem{PK: "Partition1", SK: 1}).Clauses(Returning(ReturningModifiedOld)).Update("name", "Item1")
+			},
+			want: `UPDATE "test_items" SET "name"=? WHERE "pk" = ? AND "sk" = ? RETURNING MODIFIED OLD *`,
+		},
+		"happy_path/update_gorm_returning": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Model(&testItem{PK: "Partition1", SK: 1}).Clauses(clause.Returning{}).Update("name", "Item1")
+			},
+			want: `UPDATE "test_items" SET "name"=? WHERE "pk" = ? AND "sk" = ? RETURNING ALL NEW *`,
+		},
+		"happy_path/delete_all_old": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(Returning(ReturningAllOld)).Delete(&testItem{PK: "Partition1", SK: 1})
+			},
+			want: `DELETE FROM "test_items" WHERE "pk" = ? AND "sk" = ? RETURNING ALL OLD *`,
+		},
+		"happy_path/delete_gorm_returning": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(clause.Returning{}).Delete(&testItem{PK: "Partition1", SK: 1})
+			},
+			want: `DELETE FROM "test_items" WHERE "pk" = ? AND "sk" = ? RETURNING ALL OLD *`,
+		},
+		"happy_path/without_returning": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Delete(&testItem{PK: "Partition1", SK: 1})
+			},
+			want: `DELETE FROM "test_items" WHERE "pk" = ? AND "sk" = ?`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := openTestDB(t, &fakeConnPool{}, nil, &gorm.Config{SkipDefaultTransaction: true, DryRun: true})
+			got := tt.operation(db).Statement.SQL.String()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("SQL mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReturning_Scan(t *testing.T) {
+	type want struct {
+		item         returningItem
+		rowsAffected int64
+		err          error
+	}
+	type test struct {
+		items     []map[string]types.AttributeValue
+		inUserTx  bool
+		operation func(db *gorm.DB, item *returningItem) *gorm.DB
+		want      want
+	}
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := map[string]test{
+		"happy_path/update": {
+			items: []map[string]types.AttributeValue{
+				{
+					"pk":         &types.AttributeValueMemberS{Value: "Partition1"},
+					"sk":         &types.AttributeValueMemberN{Value: "1"},
+					"name":       &types.AttributeValueMemberS{Value: "Item1"},
+					"count":      &types.AttributeValueMemberN{Value: "3"},
+					"updated_at": &types.AttributeValueMemberS{Value: "2024-01-02T03:04:05Z"},
+				},
+			},
+			operation: func(db *gorm.DB, item *returningItem) *gorm.DB {
+				return db.Model(item).Clauses(Returning(ReturningAllNew)).Update("count", gorm.Expr(`"count" + ?`, 1))
+			},
+			want: want{
+				item:         returningItem{PK: "Partition1", SK: 1, Name: "Item1", Count: 3, UpdatedAt: updatedAt},
+				rowsAffected: 1,
+			},
+		},
+		"happy_path/delete": {
+			items: []map[string]types.AttributeValue{
+				{
+					"pk":   &types.AttributeValueMemberS{Value: "Partition1"},
+					"sk":   &types.AttributeValueMemberN{Value: "1"},
+					"name": &types.AttributeValueMemberS{Value: "Item1"},
+				},
+			},
+			operation: func(db *gorm.DB, item *returningItem) *gorm.DB {
+				return db.Clauses(Returning(ReturningAllOld)).Delete(item)
+			},
+			want: want{
+				item:         returningItem{PK: "Partition1", SK: 1, Name: "Item1"},
+				rowsAffected: 1,
+			},
+		},
+		"happy_path/not_found": {
+			operation: func(db *gorm.DB, item *returningItem) *gorm.DB {
+				return db.Clauses(Returning(ReturningAllOld)).Delete(item)
+			},
+			want: want{
+				item: returningItem{PK: "Partition1", SK: 1},
+			},
+		},
+		"unhappy_path/in_transaction": {
+			inUserTx: true,
+			operation: func(db *gorm.DB, item *returningItem) *gorm.DB {
+				return db.Clauses(Returning(ReturningAllOld)).Delete(item)
+			},
+			want: want{
+				item: returningItem{PK: "Partition1", SK: 1},
+				err:  ErrReturningInTransaction,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			awsConfig := newStubAWSConfig(t, &dynamodb.ExecuteStatementOutput{Items: tt.items}, nil)
+			conn := sql.OpenDB(&connector{awsConfig: awsConfig})
+			defer conn.Close()
+			// the default transaction is not skipped
+			db := openTestDB(t, conn, nil, &gorm.Config{})
+			if tt.inUserTx {
+				db = db.Begin()
+				defer db.Rollback()
+			}
+
+			item := returningItem{PK: "Partition1", SK: 1}
+			result := tt.operation(db, &item)
+			if !errors.Is(result.Error, tt.want.err) {
+				t.Fatalf("error = %v, want %v", result.Error, tt.want.err)
+			}
+			if diff := cmp.Diff(tt.want.item, item); diff != "" {
+				t.Errorf("item mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.rowsAffected, result.RowsAffected); diff != "" {
+				t.Errorf("RowsAffected mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package dynmgrm
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"reflect"
+)
+
+// compatibility check
+var _ clause.Interface = (*allowScanExpression)(nil)
+
+var (
+	// ErrFullTableScan occurs when the statement scans the whole table or secondary index under ScanPolicyDeny.
+	ErrFullTableScan = errors.New("the statement does not pin the partition key, and would scan the whole table")
+	// ErrInvalidScanPolicy occurs when the ScanPolicy of the option is unknown.
+	ErrInvalidScanPolicy = errors.New("invalid scan policy")
+)
+
+// ScanPolicy is the policy for the statements that scan the whole table or secondary index,
+// that is, SELECT, UPDATE and DELETE whose WHERE clause does not pin the partition key.
+type ScanPolicy string
+
+const (
+	// ScanPolicyAllow sends the statements as they are.
+	ScanPolicyAllow ScanPolicy = "allow"
+	// ScanPolicyWarn sends the statements, and logs them as warnings with the logger of gorm.
+	ScanPolicyWarn ScanPolicy = "warn"
+	// ScanPolicyDeny rejects the statements with ErrFullTableScan before they are sent.
+	ScanPolicyDeny ScanPolicy = "deny"
+)
+
+// validate returns ErrInvalidScanPolicy if the policy is unknown.
+func (p ScanPolicy) validate() error {
+	switch p {
+	case ScanPolicyAllow, ScanPolicyWarn, ScanPolicyDeny:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidScanPolicy, p)
+}
+
+// WithScanPolicy sets the policy for the statements that scan the whole table or secondary index.
+// It can be overridden for each statement with AllowScan.
+//
+// The partition key is read from the tags of the model, so the statements without the model are not guarded.
+//
+// Default: ScanPolicyAllow
+func WithScanPolicy(policy ScanPolicy) func(*config) {
+	return func(config *config) {
+		config.scanPolicy = policy
+	}
+}
+
+// allowScanExpression is a clause.Interface that allows the statement to scan the whole table.
+// It is never built.
+type allowScanExpression struct{}
+
+// Name returns the name of the clause.
+func (a allowScanExpression) Name() string {
+	return "ALLOW SCAN"
+}
+
+// Build does nothing, since the clause is not a part of the statement.
+func (a allowScanExpression) Build(clause.Builder) {}
+
+// MergeClause merges the allowScanExpression into the clause.
+func (a allowScanExpression) MergeClause(clause *clause.Clause) {
+	clause.Expression = a
+}
+
+// AllowScan allows the statement to scan the whole table or secondary index, regardless of the ScanPolicy.
+//
+// e.g. db.Clauses(dynmgrm.AllowScan()).Where(`name = ?`, "Item1").Find(&items)
+func AllowScan() allowScanExpression {
+	return allowScanExpression{}
+}
+
+// buildScanGuardedClause builds the clause after guarding the statement by the ScanPolicy.
+// It is registered for UPDATE and DELETE, so that the statements are guarded whichever callback builds them.
+func buildScanGuardedClause(c clause.Clause, builder clause.Builder) {
+	if stmt, ok := builder.(*gorm.Statement); ok && !guardScan(stmt) {
+		return
+	}
+	c.Build(builder)
+}
+
+// guardScan guards the statement that scans the whole table by the ScanPolicy.
+// It reports false if the statement is rejected.
+func guardScan(stmt *gorm.Statement) bool {
+	dialector, ok := stmt.DB.Dialector.(*Dialector)
+	if !ok || dialector.scanPolicy == "" || dialector.scanPolicy == ScanPolicyAllow {
+		return true
+	}
+	if _, ok := stmt.Clauses[allowScanExpression{}.Name()]; ok {
+		return true
+	}
+	pk, _, ok := keysOf(stmt)
+	if !ok || pinsKey(stmt, pk) {
+		return true
+	}
+	switch dialector.scanPolicy {
+	case ScanPolicyDeny:
+		stmt.AddError(fmt.Errorf("%w: the condition of %s is required on %s", ErrFullTableScan, pk, stmt.Table))
+		return false
+	case ScanPolicyWarn:
+		stmt.DB.Logger.Warn(stmt.Context,
+			"dynmgrm: the statement does not pin the partition key %s, and scans the whole %s", pk, stmt.Table)
+	}
+	return true
+}
+
+// pinsKey reports whether the WHERE clause pins the key with = or IN.
+func pinsKey(stmt *gorm.Statement, key string) bool {
+	if pinsKeyByModel(stmt, key) {
+		return true
+	}
+	c, ok := stmt.Clauses["WHERE"]
+	if !ok {
+		return false
+	}
+	where, ok := c.Expression.(clause.Where)
+	if !ok {
+		return false
+	}
+	if stmt.Schema.PrioritizedPrimaryField != nil && stmt.Schema.PrioritizedPrimaryField.DBName == key {
+		// the conditions of the primary key, which gorm adds for db.Delete(&item, "pk")
+		return pinsColumn(where.Exprs, key, true) || pinsColumn(where.Exprs, clause.PrimaryKey, true)
+	}
+	return pinsColumn(where.Exprs, key, true)
+}
+
+// pinsKeyByModel reports whether the primary key of the model pins the key,
+// which gorm adds to the WHERE clause when it builds the query.
+func pinsKeyByModel(stmt *gorm.Statement, key string) bool {
+	if stmt.Schema == nil || stmt.SQL.Len() != 0 {
+		return false
+	}
+	rv := stmt.ReflectValue
+	if rv.Kind() != reflect.Struct || rv.Type() != stmt.Schema.ModelType {
+		return false
+	}
+	field := stmt.Schema.LookUpField(key)
+	if field == nil || !field.PrimaryKey {
+		return false
+	}
+	_, isZero := field.ValueOf(stmt.Context, rv)
+	return !isZero
+}
@@ -0,0 +1,197 @@
+package dynmgrm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"testing"
+)
+
+// warnRecorder is a logger.Interface that records the warnings.
+type warnRecorder struct {
+	logger.Interface
+	warnings []string
+}
+
+func (w *warnRecorder) LogMode(logger.LogLevel) logger.Interface {
+	return w
+}
+
+func (w *warnRecorder) Warn(_ context.Context, msg string, args ...interface{}) {
+	w.warnings = append(w.warnings, fmt.Sprintf(msg, args...))
+}
+
+func TestScanPolicy(t *testing.T) {
+	type want struct {
+		err      error
+		warnings []string
+	}
+	type test struct {
+		policy    ScanPolicy
+		operation func(db *gorm.DB) *gorm.DB
+		want      want
+	}
+	tests := map[string]test{
+		"happy_path/select_partition_key": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ? AND evented_at > ?`, "User1", "2024").Find(&[]orderedItem{})
+			},
+		},
+		"happy_path/select_partition_key_of_model": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(&orderedItem{UserID: "User1"}).Find(&[]orderedItem{})
+			},
+		},
+		"happy_path/first_primary_key_of_model": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.First(&testItem{PK: "Partition1", SK: 1})
+			},
+		},
+		"happy_path/select_partition_key_in": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id IN ?`, []string{"User1", "User2"}).Find(&[]orderedItem{})
+			},
+		},
+		"happy_path/select_partition_key_in_each_or": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ?`, "User1").Or(`user_id = ?`, "User2").Find(&[]orderedItem{})
+			},
+		},
+		"happy_path/select_secondary_index": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Table("ordered_items").
+					Clauses(SecondaryIndex("kind-evented_at-index")).
+					Where(`kind = ?`, "Login").
+					Find(&[]orderedItem{})
+			},
+		},
+		"happy_path/select_secondary_index_selected": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`kind = ?`, "Login").Find(&[]orderedItem{})
+			},
+		},
+		"happy_path/update_model": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Model(&testItem{PK: "Partition1", SK: 1}).Update("name", "Item1")
+			},
+		},
+		"happy_path/delete_model": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Delete(&testItem{PK: "Partition1", SK: 1})
+			},
+		},
+		"happy_path/allow_scan": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(AllowScan()).Where(`name = ?`, "Item1").Find(&[]orderedItem{})
+			},
+		},
+		"happy_path/unknown_model": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Table("ordered_items").Where(`name = ?`, "Item1").Find(&[]map[string]interface{}{})
+			},
+		},
+		"happy_path/allow": {
+			policy: ScanPolicyAllow,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`name = ?`, "Item1").Find(&[]orderedItem{})
+			},
+		},
+		"happy_path/warn": {
+			policy: ScanPolicyWarn,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`name = ?`, "Item1").Find(&[]orderedItem{})
+			},
+			want: want{
+				warnings: []string{
+					"dynmgrm: the statement does not pin the partition key user_id, and scans the whole ordered_items",
+				},
+			},
+		},
+		"unhappy_path/select_without_where": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Find(&[]orderedItem{})
+			},
+			want: want{
+				err: ErrFullTableScan,
+			},
+		},
+		"unhappy_path/select_partition_key_range": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id >= ?`, "User1").Find(&[]orderedItem{})
+			},
+			want: want{
+				err: ErrFullTableScan,
+			},
+		},
+		"unhappy_path/select_partition_key_or_other": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`user_id = ?`, "User1").Or(`name = ?`, "Item1").Find(&[]orderedItem{})
+			},
+			want: want{
+				err: ErrFullTableScan,
+			},
+		},
+		"unhappy_path/select_table_partition_key_on_secondary_index": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Table("ordered_items").
+					Clauses(SecondaryIndex("kind-evented_at-index")).
+					Where(`user_id = ?`, "User1").
+					Find(&[]orderedItem{})
+			},
+			want: want{
+				err: ErrFullTableScan,
+			},
+		},
+		"unhappy_path/update_non_key": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Model(&testItem{}).Where(`name = ?`, "Item1").Update("name", "Item2")
+			},
+			want: want{
+				err: ErrFullTableScan,
+			},
+		},
+		"unhappy_path/delete_non_key": {
+			policy: ScanPolicyDeny,
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`name = ?`, "Item1").Delete(&testItem{})
+			},
+			want: want{
+				err: ErrFullTableScan,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &warnRecorder{Interface: logger.Discard}
+			db := openTestDB(t, &fakeConnPool{}, nil, &gorm.Config{SkipDefaultTransaction: true, DryRun: true, Logger: recorder})
+			db.Dialector.(*Dialector).scanPolicy = tt.policy
+			db.Dialector.(*Dialector).indexSelection = true
+			result := tt.operation(db)
+			if !errors.Is(result.Error, tt.want.err) {
+				t.Fatalf("error = %v, want %v", result.Error, tt.want.err)
+			}
+			if diff := cmp.Diff(tt.want.warnings, recorder.warnings); diff != "" {
+				t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package dynmgrm
 
 import (
+	"errors"
 	"fmt"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -11,6 +12,13 @@ import (
 var _ clause.Expression = (*secondaryIndexExpression)(nil)
 var _ gorm.StatementModifier = (*secondaryIndexExpression)(nil)
 
+// ErrSecondaryIndexNotFound occurs when the secondary index is not declared in the tags of the model.
+var ErrSecondaryIndexNotFound = errors.New("the secondary index is not declared in the tags of the model")
+
+// secondaryIndexClauseName is the name of the clause that keeps the secondary index,
+// until the table is resolved from the model.
+const secondaryIndexClauseName = "SECONDARY INDEX"
+
 // SecondaryIndexOption is a functional option for secondaryIndexExpression
 type SecondaryIndexOption func(*secondaryIndexExpression)
 
@@ -52,6 +60,12 @@ func (s secondaryIndexExpression) ModifyStatement(stmt *gorm.Statement) {
 	if tn == "" {
 		tn = stmt.Table
 	}
+	if tn == "" {
+		// e.g. db.Model(&user).Clauses(dynmgrm.SecondaryIndex("name-index"))
+		// the table is resolved from the model when the query is built
+		stmt.Clauses[secondaryIndexClauseName] = clause.Clause{Name: secondaryIndexClauseName, Expression: s}
+		return
+	}
 
 	stmt.Table = fmt.Sprintf(`%s.%s`, tn, s.indexName)
 	qtn := &strings.Builder{}
@@ -63,6 +77,29 @@ func (s secondaryIndexExpression) ModifyStatement(stmt *gorm.Statement) {
 	}
 }
 
+// applyModelSecondaryIndex makes the query use the secondary index that has been kept until the table is resolved from the model.
+// The index is validated with the tags of the model, and ErrSecondaryIndexNotFound is added if it is not declared.
+// It reports false if the error is added.
+func applyModelSecondaryIndex(stmt *gorm.Statement) bool {
+	c, ok := stmt.Clauses[secondaryIndexClauseName]
+	if !ok {
+		return true
+	}
+	s, ok := c.Expression.(secondaryIndexExpression)
+	if !ok || stmt.Table == "" || strings.Contains(stmt.Table, ".") {
+		return true
+	}
+	if stmt.Schema != nil {
+		tablePK, _, _ := keysOf(stmt)
+		if _, ok := secondaryIndexesOf(stmt.Schema, tablePK)[s.indexName]; !ok {
+			stmt.AddError(fmt.Errorf("%w: %s of %s", ErrSecondaryIndexNotFound, s.indexName, stmt.Table))
+			return false
+		}
+	}
+	s.ModifyStatement(stmt)
+	return true
+}
+
 // Build builds the secondaryIndexExpression
 func (s secondaryIndexExpression) Build(builder clause.Builder) {
 	stmt, ok := builder.(*gorm.Statement)
@@ -55,3 +55,37 @@ func ExampleSecondaryIndex_withSecondaryIndexOf_withTableClause() {
 		Where(`gsi_key = ?`, "1").
 		Scan(&result)
 }
+
+func ExampleSecondaryIndex_withModel() {
+	db, err := gorm.Open(dynmgrm.New(), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+
+	type Event struct {
+		UserID string `dynmgrm:"pk"`
+		Kind   string `dynmgrm:"gsi-pk:kind-index"`
+	}
+	var events []Event
+	db.Model(&Event{}).Clauses(
+		dynmgrm.SecondaryIndex("kind-index")).
+		Where(`kind = ?`, "Login").
+		Find(&events)
+}
+
+func ExampleNoIndexSelection() {
+	db, err := gorm.Open(dynmgrm.New(dynmgrm.WithSecondaryIndexSelection(true)), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+
+	type Event struct {
+		UserID string `dynmgrm:"pk"`
+		Kind   string `dynmgrm:"gsi-pk:kind-index"`
+	}
+	var events []Event
+	// without NoIndexSelection, kind-index is selected automatically
+	db.Clauses(dynmgrm.NoIndexSelection()).
+		Where(`kind = ?`, "Login").
+		Find(&events)
+}
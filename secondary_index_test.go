@@ -1,6 +1,7 @@
 package dynmgrm
 
 import (
+	"errors"
 	"github.com/google/go-cmp/cmp"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -81,3 +82,106 @@ func TestSecondaryIndexExpression_Build(t *testing.T) {
 		})
 	}
 }
+
+type namedItem struct {
+	ID   string `dynmgrm:"pk"`
+	Name string `dynmgrm:"gsi-pk:name-index"`
+}
+
+func (namedItem) TableName() string {
+	return "named_items_table"
+}
+
+func TestSecondaryIndex_withModel(t *testing.T) {
+	type want struct {
+		sql string
+		err error
+	}
+	type test struct {
+		operation func(db *gorm.DB) *gorm.DB
+		want      want
+	}
+	tests := map[string]test{
+		"happy_path/model": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Model(&orderedItem{}).
+					Clauses(SecondaryIndex("kind-evented_at-index")).
+					Where(`kind = ?`, "Login").
+					Find(&[]orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items"."kind-evented_at-index" WHERE kind = ?`,
+			},
+		},
+		"happy_path/clauses_before_model": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(SecondaryIndex("kind-evented_at-index")).
+					Model(&orderedItem{}).
+					Where(`kind = ?`, "Login").
+					Find(&[]orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items"."kind-evented_at-index" WHERE kind = ?`,
+			},
+		},
+		"happy_path/dest": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(SecondaryIndex("kind-evented_at-index")).
+					Where(`kind = ?`, "Login").
+					Find(&[]orderedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "ordered_items"."kind-evented_at-index" WHERE kind = ?`,
+			},
+		},
+		"happy_path/table_name": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Model(&namedItem{}).
+					Clauses(SecondaryIndex("name-index")).
+					Where(`name = ?`, "Item1").
+					Find(&[]namedItem{})
+			},
+			want: want{
+				sql: `SELECT * FROM "named_items_table"."name-index" WHERE name = ?`,
+			},
+		},
+		"happy_path/count": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				var count int64
+				return db.Model(&orderedItem{}).
+					Clauses(SecondaryIndex("kind-evented_at-index")).
+					Where(`kind = ?`, "Login").
+					Count(&count)
+			},
+			want: want{
+				sql: `SELECT count(*) FROM "ordered_items"."kind-evented_at-index" WHERE kind = ?`,
+			},
+		},
+		"unhappy_path/index_not_declared": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Model(&orderedItem{}).
+					Clauses(SecondaryIndex("name-index")).
+					Where(`name = ?`, "Item1").
+					Find(&[]orderedItem{})
+			},
+			want: want{
+				err: ErrSecondaryIndexNotFound,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := openTestDB(t, &fakeConnPool{}, nil, &gorm.Config{SkipDefaultTransaction: true, DryRun: true})
+			result := tt.operation(db)
+			if !errors.Is(result.Error, tt.want.err) {
+				t.Fatalf("error = %v, want %v", result.Error, tt.want.err)
+			}
+			if tt.want.err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.want.sql, result.Statement.SQL.String()); diff != "" {
+				t.Errorf("SQL mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package dynmgrm
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"reflect"
+)
+
+// softDeleteFieldOf returns the gorm.DeletedAt field, or nil if it does not exist.
+func softDeleteFieldOf(sch *schema.Schema) *schema.Field {
+	if sch == nil {
+		return nil
+	}
+	for _, field := range sch.Fields {
+		if field.FieldType == reflect.TypeOf(gorm.DeletedAt{}) {
+			return field
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package dynmgrm
+
+import (
+	"database/sql"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+type softDeletableItem struct {
+	PK        string `gorm:"primaryKey"`
+	SK        int    `gorm:"primaryKey"`
+	Name      string
+	DeletedAt gorm.DeletedAt
+}
+
+func TestSoftDelete(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	type test struct {
+		operation func(db *gorm.DB) *gorm.DB
+		want      partiqlStatement
+	}
+	tests := map[string]test{
+		"happy_path/delete": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Delete(&softDeletableItem{PK: "Partition1", SK: 1})
+			},
+			want: partiqlStatement{
+				sql:  `UPDATE "soft_deletable_items" SET "deleted_at"=? WHERE "pk" = ? AND "sk" = ? AND ("deleted_at" IS MISSING OR "deleted_at" IS NULL)`,
+				vars: []interface{}{now, "Partition1", 1},
+			},
+		},
+		"happy_path/delete_with_conditions": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`pk = ?`, "Partition1").Delete(&softDeletableItem{})
+			},
+			want: partiqlStatement{
+				sql:  `UPDATE "soft_deletable_items" SET "deleted_at"=? WHERE pk = ? AND ("deleted_at" IS MISSING OR "deleted_at" IS NULL)`,
+				vars: []interface{}{now, "Partition1"},
+			},
+		},
+		"happy_path/unscoped_delete": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Unscoped().Delete(&softDeletableItem{PK: "Partition1", SK: 1})
+			},
+			want: partiqlStatement{
+				sql:  `DELETE FROM "soft_deletable_items" WHERE "pk" = ? AND "sk" = ?`,
+				vars: []interface{}{"Partition1", 1},
+			},
+		},
+		"happy_path/find": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Where(`pk = ?`, "Partition1").Find(&[]softDeletableItem{})
+			},
+			want: partiqlStatement{
+				sql:  `SELECT * FROM "soft_deletable_items" WHERE pk = ? AND ("deleted_at" IS MISSING OR "deleted_at" IS NULL)`,
+				vars: []interface{}{"Partition1"},
+			},
+		},
+		"happy_path/unscoped_find": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Unscoped().Where(`pk = ?`, "Partition1").Find(&[]softDeletableItem{})
+			},
+			want: partiqlStatement{
+				sql:  `SELECT * FROM "soft_deletable_items" WHERE pk = ?`,
+				vars: []interface{}{"Partition1"},
+			},
+		},
+		"happy_path/update": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Model(&softDeletableItem{PK: "Partition1", SK: 1}).Update("name", "Updated")
+			},
+			want: partiqlStatement{
+				sql:  `UPDATE "soft_deletable_items" SET "name"=? WHERE ("deleted_at" IS MISSING OR "deleted_at" IS NULL) AND "pk" = ? AND "sk" = ?`,
+				vars: []interface{}{"Updated", "Partition1", 1},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := openTestDB(t, &fakeConnPool{}, nil, &gorm.Config{
+				SkipDefaultTransaction: true,
+				DryRun:                 true,
+				NowFunc: func() time.Time {
+					return now
+				},
+			})
+			stmt := tt.operation(db).Statement
+			got := partiqlStatement{sql: stmt.SQL.String(), vars: stmt.Vars}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(partiqlStatement{})); diff != "" {
+				t.Errorf("statement mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSoftDelete_Scan(t *testing.T) {
+	awsConfig := newStubAWSConfig(t, &dynamodb.ExecuteStatementOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				"pk":         &types.AttributeValueMemberS{Value: "Partition1"},
+				"sk":         &types.AttributeValueMemberN{Value: "1"},
+				"name":       &types.AttributeValueMemberS{Value: "Item1"},
+				"deleted_at": &types.AttributeValueMemberS{Value: "2024-01-02T03:04:05Z"},
+			},
+			{
+				"pk":   &types.AttributeValueMemberS{Value: "Partition1"},
+				"sk":   &types.AttributeValueMemberN{Value: "2"},
+				"name": &types.AttributeValueMemberS{Value: "Item2"},
+			},
+		},
+	}, nil)
+	conn := sql.OpenDB(&connector{awsConfig: awsConfig})
+	defer conn.Close()
+	db := openTestDB(t, conn, nil, nil)
+
+	var got []softDeletableItem
+	if err := db.Unscoped().Where(`pk = ?`, "Partition1").Find(&got).Error; err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	want := []softDeletableItem{
+		{
+			PK:        "Partition1",
+			SK:        1,
+			Name:      "Item1",
+			DeletedAt: gorm.DeletedAt{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true},
+		},
+		{
+			PK:   "Partition1",
+			SK:   2,
+			Name: "Item2",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Find() mismatch (-want +got):\n%s", diff)
+	}
+}
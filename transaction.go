@@ -0,0 +1,526 @@
+package dynmgrm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/miyamo2/godynamo"
+	"gorm.io/gorm"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// compatibility check
+var (
+	_ gorm.ConnPool         = (*connPool)(nil)
+	_ gorm.ConnPoolBeginner = (*connPool)(nil)
+	_ gorm.GetDBConnector   = (*connPool)(nil)
+	_ gorm.ConnPool         = (*transaction)(nil)
+	_ gorm.TxCommitter      = (*transaction)(nil)
+	_ gorm.ConnPool         = (*driverTransaction)(nil)
+	_ gorm.TxCommitter      = (*driverTransaction)(nil)
+	_ statementBuffer       = (*transaction)(nil)
+	_ statementBuffer       = (*driverTransaction)(nil)
+)
+
+// statementBuffer is implemented by the transactions that execute their statements on commit.
+type statementBuffer interface {
+	// buffered returns the number of the statements to be executed on commit.
+	buffered() int
+}
+
+// connPool is the gorm.ConnPool of Dialector, whose transactions are executed by ExecuteTransaction of the client.
+// Without the client, the transactions of the underlying gorm.ConnPool are used instead.
+type connPool struct {
+	gorm.ConnPool
+	client      DynamoDBAPI
+	retryPolicy *RetryPolicy
+}
+
+// BeginTx begins a transaction.
+func (p *connPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	if p.client != nil {
+		return &transaction{ctx: ctx, connPool: p, client: p.client}, nil
+	}
+	if _, ok := ctx.Value(clientRequestTokenContextKey{}).(string); ok {
+		return nil, fmt.Errorf("%w: ClientRequestToken without the DynamoDB client", ErrDynmgrmAreNotSupported)
+	}
+	switch pool := p.ConnPool.(type) {
+	case gorm.TxBeginner:
+		tx, err := pool.BeginTx(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &driverTransaction{Tx: tx}, nil
+	case gorm.ConnPoolBeginner:
+		return pool.BeginTx(ctx, opts)
+	}
+	return nil, gorm.ErrInvalidTransaction
+}
+
+// GetDBConn returns the underlying *sql.DB.
+func (p *connPool) GetDBConn() (*sql.DB, error) {
+	switch conn := p.ConnPool.(type) {
+	case *sql.DB:
+		return conn, nil
+	case gorm.GetDBConnector:
+		return conn.GetDBConn()
+	}
+	return nil, nil
+}
+
+// driverTransaction is the transaction of the driver, which is used without the client.
+type driverTransaction struct {
+	*sql.Tx
+	executed int
+}
+
+// ExecContext adds the statement to the transaction.
+func (t *driverTransaction) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := t.Tx.ExecContext(ctx, query, args...)
+	if err == nil {
+		t.executed++
+	}
+	return result, err
+}
+
+// buffered returns the number of the statements added to the transaction.
+func (t *driverTransaction) buffered() int {
+	return t.executed
+}
+
+// Commit commits the transaction.
+// If DynamoDB cancels it, *TransactionCanceledError is returned with the cancellation reasons.
+func (t *driverTransaction) Commit() error {
+	err := t.Tx.Commit()
+	if tce := (*types.TransactionCanceledException)(nil); errors.As(err, &tce) {
+		return newTransactionCanceledError(err, tce)
+	}
+	return err
+}
+
+var (
+	// ErrMixedReadWriteTransaction occurs when a transaction has both reads and writes,
+	// since ExecuteTransaction of DynamoDB accepts either only SELECT statements or only the others.
+	ErrMixedReadWriteTransaction = errors.New("transactions cannot mix reads and writes")
+	// ErrTransactionLimitExceeded occurs when a transaction exceeds the limits of ExecuteTransaction.
+	ErrTransactionLimitExceeded = errors.New("transaction exceeds the limit of DynamoDB")
+	// ErrInvalidClientRequestToken occurs when ClientRequestToken of a transaction is not accepted by DynamoDB.
+	ErrInvalidClientRequestToken = errors.New("invalid ClientRequestToken")
+)
+
+const (
+	// maxTransactionStatements is the maximum number of the statements in a transaction.
+	maxTransactionStatements = 100
+	// maxTransactionSize is the maximum total size of the statements in a transaction.
+	maxTransactionSize = 4 * 1024 * 1024
+	// maxClientRequestTokenLength is the maximum length of ClientRequestToken.
+	maxClientRequestTokenLength = 36
+)
+
+// clientRequestTokenContextKey is the context key for ClientRequestToken of transactions.
+type clientRequestTokenContextKey struct{}
+
+// ClientRequestTokenContext returns a copy of ctx with which the transactions are committed with the ClientRequestToken.
+// DynamoDB applies the transactions with the same token only once within 10 minutes,
+// so that a retried commit is not applied twice.
+// The token must be 1 to 36 characters, and requires the DynamoDB client.
+//
+// e.g. db.WithContext(dynmgrm.ClientRequestTokenContext(ctx, token)).Transaction(fn)
+func ClientRequestTokenContext(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, clientRequestTokenContextKey{}, token)
+}
+
+// transaction is a gorm.ConnPool that buffers the statements, and executes them by ExecuteTransaction on commit.
+//
+// Reads with Find/First are also buffered, and their destinations are filled on commit.
+type transaction struct {
+	ctx        context.Context
+	connPool   gorm.ConnPool
+	client     DynamoDBAPI
+	statements []partiqlStatement
+	reads      []transactionRead
+	done       bool
+}
+
+// transactionRead is a query in the transaction.
+// The destination is kept, since the statement of the query may be reused by the following queries.
+type transactionRead struct {
+	partiqlStatement
+	db           *gorm.DB
+	dest         interface{}
+	reflectValue reflect.Value
+}
+
+// PrepareContext is not supported in transactions.
+func (t *transaction) PrepareContext(_ context.Context, _ string) (*sql.Stmt, error) {
+	return nil, ErrDynmgrmAreNotSupported
+}
+
+// ExecContext adds the statement to the transaction.
+func (t *transaction) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if t.done {
+		return nil, sql.ErrTxDone
+	}
+	if len(t.reads) > 0 {
+		return nil, ErrMixedReadWriteTransaction
+	}
+	if len(t.statements) >= maxTransactionStatements {
+		return nil, fmt.Errorf("%w: the number of statements is up to %d", ErrTransactionLimitExceeded, maxTransactionStatements)
+	}
+	t.statements = append(t.statements, partiqlStatement{sql: query, vars: args})
+	return transactionResult{}, nil
+}
+
+// buffered returns the number of the statements added to the transaction.
+func (t *transaction) buffered() int {
+	return len(t.statements)
+}
+
+// addRead adds the query to the transaction.
+// The destination of the query is filled on commit.
+func (t *transaction) addRead(db *gorm.DB) error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	if len(t.statements) > 0 {
+		return ErrMixedReadWriteTransaction
+	}
+	if len(t.reads) >= maxTransactionStatements {
+		return fmt.Errorf("%w: the number of statements is up to %d", ErrTransactionLimitExceeded, maxTransactionStatements)
+	}
+	t.reads = append(t.reads, transactionRead{
+		partiqlStatement: partiqlStatement{sql: db.Statement.SQL.String(), vars: slices.Clone(db.Statement.Vars)},
+		db:               db,
+		dest:             db.Statement.Dest,
+		reflectValue:     db.Statement.ReflectValue,
+	})
+	return nil
+}
+
+// QueryContext issues the query to the underlying connection, outside the transaction.
+func (t *transaction) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return t.connPool.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext issues the query to the underlying connection, outside the transaction.
+func (t *transaction) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return t.connPool.QueryRowContext(ctx, query, args...)
+}
+
+// Commit executes the statements by ExecuteTransaction.
+//
+// If the transaction is canceled, *TransactionCanceledError is returned.
+func (t *transaction) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if len(t.reads) > 0 {
+		return t.commitReads()
+	}
+	if len(t.statements) == 0 {
+		return nil
+	}
+	input := &dynamodb.ExecuteTransactionInput{}
+	if token, ok := t.ctx.Value(clientRequestTokenContextKey{}).(string); ok {
+		if len(token) == 0 || len(token) > maxClientRequestTokenLength {
+			return fmt.Errorf("%w: the length is from 1 to %d", ErrInvalidClientRequestToken, maxClientRequestTokenLength)
+		}
+		input.ClientRequestToken = aws.String(token)
+	}
+	size := 0
+	statements := make([]types.ParameterizedStatement, 0, len(t.statements))
+	for _, stmt := range t.statements {
+		params, err := stmt.parameters()
+		if err != nil {
+			return err
+		}
+		size += len(stmt.sql)
+		for _, param := range params {
+			size += attributeValueSize(param)
+		}
+		statement := types.ParameterizedStatement{
+			Statement:                           aws.String(stmt.sql),
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		}
+		if len(params) > 0 {
+			statement.Parameters = params
+		}
+		statements = append(statements, statement)
+	}
+	if size > maxTransactionSize {
+		return fmt.Errorf("%w: the total size of statements is up to 4 MB, but is %d bytes", ErrTransactionLimitExceeded, size)
+	}
+	input.TransactStatements = statements
+	_, err := t.client.ExecuteTransaction(t.ctx, input)
+	var tce *types.TransactionCanceledException
+	if errors.As(err, &tce) {
+		return newTransactionCanceledError(err, tce)
+	}
+	return err
+}
+
+// commitReads executes the reads by ExecuteTransaction, and scans the items into their destinations.
+// DynamoDB reads the items atomically, as TransactGetItems does.
+func (t *transaction) commitReads() error {
+	statements := make([]types.ParameterizedStatement, 0, len(t.reads))
+	for _, read := range t.reads {
+		params, err := read.parameters()
+		if err != nil {
+			return err
+		}
+		statement := types.ParameterizedStatement{Statement: aws.String(read.sql)}
+		if len(params) > 0 {
+			statement.Parameters = params
+		}
+		statements = append(statements, statement)
+	}
+	output, err := t.client.ExecuteTransaction(t.ctx, &dynamodb.ExecuteTransactionInput{
+		TransactStatements: statements,
+	})
+	var tce *types.TransactionCanceledException
+	if errors.As(err, &tce) {
+		return newTransactionCanceledError(err, tce)
+	}
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i, read := range t.reads {
+		items := make([]map[string]types.AttributeValue, 0, 1)
+		if i < len(output.Responses) && len(output.Responses[i].Item) > 0 {
+			items = append(items, output.Responses[i].Item)
+		}
+		db := read.db
+		db.Error = nil
+		db.Statement.Dest = read.dest
+		db.Statement.ReflectValue = read.reflectValue
+		// First/Take/Last that have found no item add gorm.ErrRecordNotFound
+		gorm.Scan(&timeRows{Rows: newItemRows(items)}, db, 0)
+		if db.Error != nil {
+			errs = append(errs, db.Error)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Rollback discards the statements.
+func (t *transaction) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	t.statements = nil
+	t.reads = nil
+	return nil
+}
+
+// transactionResult is the result of the statements in transactions.
+// Since they are executed on commit, the number of affected items is unknown.
+type transactionResult struct{}
+
+// LastInsertId is not supported.
+func (r transactionResult) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+// RowsAffected returns godynamo.ErrInTx, as godynamo does.
+func (r transactionResult) RowsAffected() (int64, error) {
+	return 0, godynamo.ErrInTx
+}
+
+// TransactionCanceledReason is the reason why a statement in the transaction was canceled.
+type TransactionCanceledReason struct {
+	// Code is the cancellation code, e.g. "ConditionalCheckFailed", "TransactionConflict" or "ThrottlingError".
+	// It is "None" for the statements that did not cause the cancellation.
+	Code string
+	// Message is the cancellation message.
+	Message string
+	// Item is the existing item, if the condition of the statement has failed.
+	Item map[string]any
+}
+
+// TransactionCanceledError occurs when DynamoDB cancels the transaction.
+type TransactionCanceledError struct {
+	// Reasons are the reasons for each statement, in the order the statements were added to the transaction.
+	Reasons []TransactionCanceledReason
+	err     error
+}
+
+func (e *TransactionCanceledError) Error() string {
+	reasons := make([]string, 0, len(e.Reasons))
+	for i, reason := range e.Reasons {
+		if reason.Code == "None" || reason.Code == "" {
+			continue
+		}
+		if reason.Message == "" {
+			reasons = append(reasons, fmt.Sprintf("[%d] %s", i, reason.Code))
+			continue
+		}
+		reasons = append(reasons, fmt.Sprintf("[%d] %s: %s", i, reason.Code, reason.Message))
+	}
+	return fmt.Sprintf("transaction canceled: %s", strings.Join(reasons, ", "))
+}
+
+// Unwrap returns the original error, which has *types.TransactionCanceledException.
+func (e *TransactionCanceledError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the target is ErrConditionFailed and a condition of the statements has failed.
+func (e *TransactionCanceledError) Is(target error) bool {
+	if target != ErrConditionFailed {
+		return false
+	}
+	for _, reason := range e.Reasons {
+		if reason.Code == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
+
+// Codes returns the cancellation codes in the order the statements were added to the transaction.
+func (e *TransactionCanceledError) Codes() []string {
+	codes := make([]string, 0, len(e.Reasons))
+	for _, reason := range e.Reasons {
+		codes = append(codes, reason.Code)
+	}
+	return codes
+}
+
+// newTransactionCanceledError returns *TransactionCanceledError with the cancellation reasons of the exception.
+func newTransactionCanceledError(err error, tce *types.TransactionCanceledException) *TransactionCanceledError {
+	reasons := make([]TransactionCanceledReason, 0, len(tce.CancellationReasons))
+	for _, cr := range tce.CancellationReasons {
+		reason := TransactionCanceledReason{
+			Code:    aws.ToString(cr.Code),
+			Message: aws.ToString(cr.Message),
+		}
+		if len(cr.Item) > 0 {
+			var item map[string]any
+			if attributevalue.UnmarshalMap(cr.Item, &item) == nil {
+				reason.Item = item
+			}
+		}
+		reasons = append(reasons, reason)
+	}
+	return &TransactionCanceledError{Reasons: reasons, err: err}
+}
+
+// TransactionOption is the option for RunTransaction.
+type TransactionOption func(*RetryPolicy)
+
+// WithTransactionMaxAttempts sets the maximum number of attempts of RunTransaction, including the first one.
+//
+// Default: 5
+func WithTransactionMaxAttempts(attempts int) func(*RetryPolicy) {
+	return func(policy *RetryPolicy) {
+		policy.MaxAttempts = attempts
+	}
+}
+
+// WithTransactionBackoff sets the upper bounds of the first backoff and of all backoffs of RunTransaction.
+// The backoff is chosen randomly below the upper bound, which doubles on each retry.
+//
+// Default: 50ms and 5s
+func WithTransactionBackoff(initial, maximum time.Duration) func(*RetryPolicy) {
+	return func(policy *RetryPolicy) {
+		policy.InitialInterval = initial
+		policy.MaxInterval = maximum
+	}
+}
+
+// RunTransaction runs fn in a transaction, like gorm.DB.Transaction does.
+// If DynamoDB cancels the transaction with TransactionConflict, fn is run again in a new transaction.
+//
+// The transactions canceled for the other reasons, and the errors returned by fn are not retried.
+//
+// e.g.
+//
+//	err := dynmgrm.RunTransaction(db, func(tx *gorm.DB) error {
+//		return tx.Model(&account).Update("balance", gorm.Expr("balance - ?", 100)).Error
+//	}, dynmgrm.WithTransactionMaxAttempts(3))
+func RunTransaction(db *gorm.DB, fn func(tx *gorm.DB) error, opts ...TransactionOption) error {
+	policy := RetryPolicy{}
+	for _, opt := range opts {
+		opt(&policy)
+	}
+	ctx := context.Background()
+	if db.Statement != nil && db.Statement.Context != nil {
+		ctx = db.Statement.Context
+	}
+	return policy.do(ctx, isTransactionConflict, func() error {
+		return db.Transaction(fn)
+	})
+}
+
+// isTransactionConflict reports whether the transaction has been canceled only by TransactionConflict.
+func isTransactionConflict(err error) bool {
+	var tce *TransactionCanceledError
+	if !errors.As(err, &tce) {
+		return false
+	}
+	conflicted := false
+	for _, code := range tce.Codes() {
+		switch code {
+		case "", "None":
+		case "TransactionConflict":
+			conflicted = true
+		default:
+			return false
+		}
+	}
+	return conflicted
+}
+
+// attributeValueSize returns the approximate size of the attribute value, as DynamoDB calculates the item size.
+func attributeValueSize(av types.AttributeValue) int {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return len(v.Value)
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberSS:
+		size := 0
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		size := 0
+		for _, n := range v.Value {
+			size += len(n)
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		size := 0
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		size := 3
+		for _, e := range v.Value {
+			size += 1 + attributeValueSize(e)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		size := 3
+		for k, e := range v.Value {
+			size += 1 + len(k) + attributeValueSize(e)
+		}
+		return size
+	}
+	return 1
+}
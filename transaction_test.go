@@ -0,0 +1,676 @@
+package dynmgrm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/miyamo2/dynmgrm/internal/mocks"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransaction(t *testing.T) {
+	type want struct {
+		input   *dynamodb.ExecuteTransactionInput
+		reasons []TransactionCanceledReason
+		err     error
+	}
+	type test struct {
+		fn     func(tx *gorm.DB) error
+		output *dynamodb.ExecuteTransactionOutput
+		err    error
+		want   want
+	}
+	errRollback := errors.New("rollback")
+	errInternalServer := &types.InternalServerError{Message: aws.String("internal server error")}
+	write := func(tx *gorm.DB) error {
+		if err := tx.Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&testItem{PK: "Partition1", SK: 2}).Update("name", "Item2").Error
+	}
+	input := &dynamodb.ExecuteTransactionInput{
+		TransactStatements: []types.ParameterizedStatement{
+			{
+				Statement: aws.String(`INSERT INTO "test_items" VALUE {'pk' : ?, 'sk' : ?, 'name' : ?}`),
+				Parameters: []types.AttributeValue{
+					&types.AttributeValueMemberS{Value: "Partition1"},
+					&types.AttributeValueMemberN{Value: "1"},
+					&types.AttributeValueMemberS{Value: "Item1"},
+				},
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+			},
+			{
+				Statement: aws.String(`UPDATE "test_items" SET "name"=? WHERE "pk" = ? AND "sk" = ?`),
+				Parameters: []types.AttributeValue{
+					&types.AttributeValueMemberS{Value: "Item2"},
+					&types.AttributeValueMemberS{Value: "Partition1"},
+					&types.AttributeValueMemberN{Value: "2"},
+				},
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+			},
+		},
+	}
+	tests := map[string]test{
+		"happy_path/commit": {
+			fn:     write,
+			output: &dynamodb.ExecuteTransactionOutput{},
+			want: want{
+				input: input,
+			},
+		},
+		"happy_path/empty": {
+			fn: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		"happy_path/rollback": {
+			fn: func(tx *gorm.DB) error {
+				if err := write(tx); err != nil {
+					return err
+				}
+				return errRollback
+			},
+			want: want{
+				err: errRollback,
+			},
+		},
+		"unhappy_path/canceled": {
+			fn: write,
+			err: &types.TransactionCanceledException{
+				Message: aws.String("Transaction cancelled"),
+				CancellationReasons: []types.CancellationReason{
+					{
+						Code:    aws.String("ConditionalCheckFailed"),
+						Message: aws.String("The conditional request failed"),
+						Item: map[string]types.AttributeValue{
+							"pk":   &types.AttributeValueMemberS{Value: "Partition1"},
+							"sk":   &types.AttributeValueMemberN{Value: "1"},
+							"name": &types.AttributeValueMemberS{Value: "Existing"},
+						},
+					},
+					{
+						Code: aws.String("None"),
+					},
+				},
+			},
+			want: want{
+				input: input,
+				reasons: []TransactionCanceledReason{
+					{
+						Code:    "ConditionalCheckFailed",
+						Message: "The conditional request failed",
+						Item: map[string]any{
+							"pk":   "Partition1",
+							"sk":   float64(1),
+							"name": "Existing",
+						},
+					},
+					{
+						Code: "None",
+					},
+				},
+				err: &types.TransactionCanceledException{},
+			},
+		},
+		"unhappy_path/other_error": {
+			fn:  write,
+			err: errInternalServer,
+			want: want{
+				input: input,
+				err:   errInternalServer,
+			},
+		},
+	}
+	opts := []cmp.Option{
+		cmpopts.IgnoreUnexported(
+			dynamodb.ExecuteTransactionInput{},
+			types.ParameterizedStatement{},
+			types.AttributeValueMemberS{},
+			types.AttributeValueMemberN{},
+		),
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockDynamoDBAPI(ctrl)
+			var input *dynamodb.ExecuteTransactionInput
+			if tt.want.input != nil {
+				client.EXPECT().ExecuteTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *dynamodb.ExecuteTransactionInput, _ ...func(*dynamodb.Options)) (*dynamodb.ExecuteTransactionOutput, error) {
+						input = in
+						return tt.output, tt.err
+					}).Times(1)
+			}
+			db := openTestDB(t, &fakeConnPool{}, client, nil)
+
+			err := db.Transaction(tt.fn)
+			if tt.want.err == nil && err != nil {
+				t.Fatalf("Transaction() error = %v, want nil", err)
+			}
+			if tt.want.err != nil {
+				var tce *types.TransactionCanceledException
+				if errors.As(tt.want.err, &tce) {
+					if !errors.As(err, &tce) {
+						t.Fatalf("Transaction() error = %v, want TransactionCanceledException", err)
+					}
+				} else if !errors.Is(err, tt.want.err) {
+					t.Fatalf("Transaction() error = %v, want %v", err, tt.want.err)
+				}
+			}
+			if diff := cmp.Diff(tt.want.input, input, opts...); diff != "" {
+				t.Errorf("ExecuteTransactionInput mismatch (-want +got):\n%s", diff)
+			}
+			var tce *TransactionCanceledError
+			if errors.As(err, &tce) {
+				if diff := cmp.Diff(tt.want.reasons, tce.Reasons); diff != "" {
+					t.Errorf("Reasons mismatch (-want +got):\n%s", diff)
+				}
+			} else if tt.want.reasons != nil {
+				t.Errorf("Transaction() error = %v, want TransactionCanceledError", err)
+			}
+		})
+	}
+}
+
+func TestTransaction_withoutClient(t *testing.T) {
+	type want struct {
+		execs   []partiqlStatement
+		commits int
+		reasons []TransactionCanceledReason
+		err     error
+	}
+	type test struct {
+		token     *string
+		commitErr error
+		want      want
+	}
+	errCommit := errors.New("commit")
+	tests := map[string]test{
+		"happy_path/commit": {
+			want: want{
+				execs: []partiqlStatement{
+					{
+						sql:  `INSERT INTO "test_items" VALUE {'pk' : ?, 'sk' : ?, 'name' : ?}`,
+						vars: []interface{}{"Partition1", 1, "Item1"},
+					},
+				},
+				commits: 1,
+			},
+		},
+		"unhappy_path/commit_error": {
+			commitErr: errCommit,
+			want: want{
+				execs: []partiqlStatement{
+					{
+						sql:  `INSERT INTO "test_items" VALUE {'pk' : ?, 'sk' : ?, 'name' : ?}`,
+						vars: []interface{}{"Partition1", 1, "Item1"},
+					},
+				},
+				commits: 1,
+				err:     errCommit,
+			},
+		},
+		"unhappy_path/client_request_token": {
+			token: aws.String("token"),
+			want: want{
+				err: ErrDynmgrmAreNotSupported,
+			},
+		},
+		"unhappy_path/canceled": {
+			commitErr: &types.TransactionCanceledException{
+				Message: aws.String("Transaction cancelled"),
+				CancellationReasons: []types.CancellationReason{
+					{Code: aws.String("ConditionalCheckFailed"), Message: aws.String("The conditional request failed")},
+				},
+			},
+			want: want{
+				execs: []partiqlStatement{
+					{
+						sql:  `INSERT INTO "test_items" VALUE {'pk' : ?, 'sk' : ?, 'name' : ?}`,
+						vars: []interface{}{"Partition1", 1, "Item1"},
+					},
+				},
+				commits: 1,
+				reasons: []TransactionCanceledReason{
+					{Code: "ConditionalCheckFailed", Message: "The conditional request failed"},
+				},
+			},
+		},
+	}
+	opts := []cmp.Option{
+		cmp.AllowUnexported(partiqlStatement{}),
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			drv := &fakeTxDriver{commitErr: tt.commitErr}
+			db := openTestDB(t, sql.OpenDB(drv), nil, nil)
+			if tt.token != nil {
+				db = db.WithContext(ClientRequestTokenContext(context.Background(), *tt.token))
+			}
+
+			err := db.Transaction(func(tx *gorm.DB) error {
+				return tx.Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"}).Error
+			})
+			if tt.want.err != nil && !errors.Is(err, tt.want.err) {
+				t.Fatalf("Transaction() error = %v, want %v", err, tt.want.err)
+			}
+			if tt.want.err == nil && tt.want.reasons == nil && err != nil {
+				t.Fatalf("Transaction() error = %v, want nil", err)
+			}
+			if diff := cmp.Diff(tt.want.execs, drv.execs, opts...); diff != "" {
+				t.Errorf("executed statements mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.commits, drv.commits); diff != "" {
+				t.Errorf("commits mismatch (-want +got):\n%s", diff)
+			}
+			if tt.want.reasons == nil {
+				return
+			}
+			var tce *TransactionCanceledError
+			if !errors.As(err, &tce) {
+				t.Fatalf("Transaction() error = %v, want TransactionCanceledError", err)
+			}
+			if diff := cmp.Diff(tt.want.reasons, tce.Reasons); diff != "" {
+				t.Errorf("Reasons mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// fakeTxDriver is a driver.Connector whose transactions record executed statements like the one of godynamo.
+type fakeTxDriver struct {
+	execs     []partiqlStatement
+	commits   int
+	commitErr error
+}
+
+func (d *fakeTxDriver) Connect(_ context.Context) (driver.Conn, error) {
+	return &fakeTxConn{driver: d}, nil
+}
+
+func (d *fakeTxDriver) Driver() driver.Driver {
+	return nil
+}
+
+type fakeTxConn struct {
+	driver *fakeTxDriver
+}
+
+func (c *fakeTxConn) Prepare(_ string) (driver.Stmt, error) {
+	return nil, errFakeConnPoolQueryFail
+}
+
+func (c *fakeTxConn) Close() error {
+	return nil
+}
+
+func (c *fakeTxConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *fakeTxConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	vars := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		vars = append(vars, arg.Value)
+	}
+	c.driver.execs = append(c.driver.execs, partiqlStatement{sql: query, vars: vars})
+	return &fakeResult{rowsAffected: 1}, nil
+}
+
+func (c *fakeTxConn) CheckNamedValue(_ *driver.NamedValue) error {
+	return nil
+}
+
+func (c *fakeTxConn) Commit() error {
+	c.driver.commits++
+	return c.driver.commitErr
+}
+
+func (c *fakeTxConn) Rollback() error {
+	return nil
+}
+
+func TestTransactionCanceledError_Error(t *testing.T) {
+	err := &TransactionCanceledError{
+		Reasons: []TransactionCanceledReason{
+			{Code: "None"},
+			{Code: "ConditionalCheckFailed", Message: "The conditional request failed"},
+			{Code: "TransactionConflict"},
+		},
+	}
+	want := "transaction canceled: [1] ConditionalCheckFailed: The conditional request failed, [2] TransactionConflict"
+	if diff := cmp.Diff(want, err.Error()); diff != "" {
+		t.Errorf("Error() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunTransaction(t *testing.T) {
+	type want struct {
+		runs int
+		err  error
+	}
+	type test struct {
+		fnErr error
+		errs  []error
+		opts  []TransactionOption
+		want  want
+	}
+	canceled := func(codes ...string) error {
+		reasons := make([]types.CancellationReason, 0, len(codes))
+		for _, code := range codes {
+			reasons = append(reasons, types.CancellationReason{Code: aws.String(code)})
+		}
+		return &types.TransactionCanceledException{CancellationReasons: reasons}
+	}
+	errUser := errors.New("user error")
+	tests := map[string]test{
+		"happy_path/committed": {
+			errs: []error{nil},
+			want: want{runs: 1},
+		},
+		"happy_path/conflicted_then_committed": {
+			errs: []error{canceled("TransactionConflict", "None"), canceled("None", "TransactionConflict"), nil},
+			want: want{runs: 3},
+		},
+		"unhappy_path/condition_failed": {
+			errs: []error{canceled("TransactionConflict", "ConditionalCheckFailed")},
+			want: want{runs: 1, err: &TransactionCanceledError{}},
+		},
+		"unhappy_path/user_error": {
+			fnErr: errUser,
+			want:  want{runs: 1, err: errUser},
+		},
+		"unhappy_path/max_attempts": {
+			errs: []error{canceled("TransactionConflict"), canceled("TransactionConflict")},
+			opts: []TransactionOption{WithTransactionMaxAttempts(2), WithTransactionBackoff(time.Millisecond, time.Millisecond)},
+			want: want{runs: 2, err: &TransactionCanceledError{}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			stubSleep(t)
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockDynamoDBAPI(ctrl)
+			for _, err := range tt.errs {
+				client.EXPECT().ExecuteTransaction(gomock.Any(), gomock.Any()).
+					Return(&dynamodb.ExecuteTransactionOutput{}, err).Times(1)
+			}
+			db := openTestDB(t, &fakeConnPool{}, client, nil)
+
+			runs := 0
+			err := RunTransaction(db, func(tx *gorm.DB) error {
+				runs++
+				if err := tx.Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"}).Error; err != nil {
+					return err
+				}
+				return tt.fnErr
+			}, tt.opts...)
+			switch want := tt.want.err.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("RunTransaction() error = %v, want nil", err)
+				}
+			case *TransactionCanceledError:
+				if !errors.As(err, &want) {
+					t.Fatalf("RunTransaction() error = %v, want TransactionCanceledError", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("RunTransaction() error = %v, want %v", err, want)
+				}
+			}
+			if diff := cmp.Diff(tt.want.runs, runs); diff != "" {
+				t.Errorf("runs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTransaction_reads(t *testing.T) {
+	type want struct {
+		statements []string
+		item       testItem
+		items      []pageItem
+		err        error
+	}
+	type test struct {
+		fn     func(tx *gorm.DB, item *testItem, items *[]pageItem) error
+		output *dynamodb.ExecuteTransactionOutput
+		want   want
+	}
+	read := func(tx *gorm.DB, item *testItem, items *[]pageItem) error {
+		if err := tx.Where(`pk = ? AND sk = ?`, "Partition1", 1).First(item).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(ConsistentRead()).Where(`pk = ? AND sk = ?`, "Partition1", 2).Find(items).Error
+	}
+	statements := []string{
+		`SELECT * FROM "test_items" WHERE pk = ? AND sk = ?`,
+		`SELECT * FROM "page_items" WHERE pk = ? AND sk = ?`,
+	}
+	tests := map[string]test{
+		"happy_path/read": {
+			fn: read,
+			output: &dynamodb.ExecuteTransactionOutput{
+				Responses: []types.ItemResponse{
+					{Item: map[string]types.AttributeValue{
+						"pk":   &types.AttributeValueMemberS{Value: "Partition1"},
+						"sk":   &types.AttributeValueMemberN{Value: "1"},
+						"name": &types.AttributeValueMemberS{Value: "Item1"},
+					}},
+					{Item: map[string]types.AttributeValue{
+						"pk":   &types.AttributeValueMemberS{Value: "Partition1"},
+						"sk":   &types.AttributeValueMemberN{Value: "2"},
+						"name": &types.AttributeValueMemberS{Value: "Item2"},
+					}},
+				},
+			},
+			want: want{
+				statements: statements,
+				item:       testItem{PK: "Partition1", SK: 1, Name: "Item1"},
+				items:      []pageItem{{PK: "Partition1", SK: 2, Name: aws.String("Item2")}},
+			},
+		},
+		"unhappy_path/record_not_found": {
+			fn: read,
+			output: &dynamodb.ExecuteTransactionOutput{
+				Responses: []types.ItemResponse{{}, {}},
+			},
+			want: want{
+				statements: statements,
+				items:      []pageItem{},
+				err:        gorm.ErrRecordNotFound,
+			},
+		},
+		"unhappy_path/read_after_write": {
+			fn: func(tx *gorm.DB, item *testItem, items *[]pageItem) error {
+				if err := tx.Create(&testItem{PK: "Partition1", SK: 1}).Error; err != nil {
+					return err
+				}
+				return read(tx, item, items)
+			},
+			want: want{
+				err: ErrMixedReadWriteTransaction,
+			},
+		},
+		"unhappy_path/write_after_read": {
+			fn: func(tx *gorm.DB, item *testItem, items *[]pageItem) error {
+				if err := read(tx, item, items); err != nil {
+					return err
+				}
+				return tx.Create(&testItem{PK: "Partition1", SK: 1}).Error
+			},
+			want: want{
+				err: ErrMixedReadWriteTransaction,
+			},
+		},
+		"unhappy_path/too_many_reads": {
+			fn: func(tx *gorm.DB, item *testItem, items *[]pageItem) error {
+				for i := 0; i <= maxTransactionStatements; i++ {
+					if err := tx.Where(`pk = ? AND sk = ?`, "Partition1", i).Find(items).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			want: want{
+				err: ErrTransactionLimitExceeded,
+			},
+		},
+		"unhappy_path/page": {
+			fn: func(tx *gorm.DB, item *testItem, items *[]pageItem) error {
+				return tx.Clauses(Page(10, "")).Where(`pk = ?`, "Partition1").Find(items).Error
+			},
+			want: want{
+				err: ErrDynmgrmAreNotSupported,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockDynamoDBAPI(ctrl)
+			var got []string
+			if tt.output != nil {
+				client.EXPECT().ExecuteTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *dynamodb.ExecuteTransactionInput, _ ...func(*dynamodb.Options)) (*dynamodb.ExecuteTransactionOutput, error) {
+						for _, statement := range in.TransactStatements {
+							got = append(got, aws.ToString(statement.Statement))
+						}
+						return tt.output, nil
+					}).Times(1)
+			}
+			db := openTestDB(t, &fakeConnPool{}, client, nil)
+
+			var item testItem
+			var items []pageItem
+			err := db.Transaction(func(tx *gorm.DB) error {
+				return tt.fn(tx, &item, &items)
+			})
+			if !errors.Is(err, tt.want.err) {
+				t.Fatalf("Transaction() error = %v, want %v", err, tt.want.err)
+			}
+			if diff := cmp.Diff(tt.want.statements, got); diff != "" {
+				t.Errorf("statements mismatch (-want +got):\n%s", diff)
+			}
+			if tt.want.err != nil && tt.output == nil {
+				return
+			}
+			if diff := cmp.Diff(tt.want.item, item); diff != "" {
+				t.Errorf("item mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.items, items); diff != "" {
+				t.Errorf("items mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTransaction_clientRequestTokenAndLimits(t *testing.T) {
+	type want struct {
+		token *string
+		err   error
+	}
+	type test struct {
+		token *string
+		fn    func(tx *gorm.DB) error
+		want  want
+	}
+	create := func(tx *gorm.DB) error {
+		return tx.Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"}).Error
+	}
+	tests := map[string]test{
+		"happy_path/without_token": {
+			fn:   create,
+			want: want{},
+		},
+		"happy_path/with_token": {
+			token: aws.String("token"),
+			fn:    create,
+			want: want{
+				token: aws.String("token"),
+			},
+		},
+		"unhappy_path/empty_token": {
+			token: aws.String(""),
+			fn:    create,
+			want: want{
+				err: ErrInvalidClientRequestToken,
+			},
+		},
+		"unhappy_path/too_long_token": {
+			token: aws.String(strings.Repeat("t", maxClientRequestTokenLength+1)),
+			fn:    create,
+			want: want{
+				err: ErrInvalidClientRequestToken,
+			},
+		},
+		"unhappy_path/too_many_statements": {
+			fn: func(tx *gorm.DB) error {
+				for i := 0; i <= maxTransactionStatements; i++ {
+					if err := tx.Create(&testItem{PK: "Partition1", SK: i}).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			want: want{
+				err: ErrTransactionLimitExceeded,
+			},
+		},
+		"unhappy_path/too_large": {
+			fn: func(tx *gorm.DB) error {
+				for i := 0; i < 5; i++ {
+					item := testItem{PK: "Partition1", SK: i, Name: strings.Repeat("n", 1024*1024)}
+					if err := tx.Create(&item).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			want: want{
+				err: ErrTransactionLimitExceeded,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockDynamoDBAPI(ctrl)
+			var input *dynamodb.ExecuteTransactionInput
+			if tt.want.err == nil {
+				client.EXPECT().ExecuteTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *dynamodb.ExecuteTransactionInput, _ ...func(*dynamodb.Options)) (*dynamodb.ExecuteTransactionOutput, error) {
+						input = in
+						return &dynamodb.ExecuteTransactionOutput{}, nil
+					}).Times(1)
+			}
+			db := openTestDB(t, &fakeConnPool{}, client, nil)
+			ctx := context.Background()
+			if tt.token != nil {
+				ctx = ClientRequestTokenContext(ctx, *tt.token)
+			}
+
+			err := db.WithContext(ctx).Transaction(tt.fn)
+			if !errors.Is(err, tt.want.err) {
+				t.Fatalf("Transaction() error = %v, want %v", err, tt.want.err)
+			}
+			if tt.want.err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.want.token, input.ClientRequestToken); diff != "" {
+				t.Errorf("ClientRequestToken mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
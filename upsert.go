@@ -0,0 +1,150 @@
+package dynmgrm
+
+import (
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/miyamo2/godynamo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"slices"
+)
+
+// onConflictOf returns the ON CONFLICT clause of the statement.
+func onConflictOf(stmt *gorm.Statement) (clause.OnConflict, bool) {
+	c, ok := stmt.Clauses["ON CONFLICT"]
+	if !ok {
+		return clause.OnConflict{}, false
+	}
+	onConflict, ok := c.Expression.(clause.OnConflict)
+	return onConflict, ok
+}
+
+// upsert writes the items with ON CONFLICT.
+//
+// With UpdateAll, the items are written by PutItem, which inserts the missing items and overwrites the existing ones.
+// With DoNothing, the items are inserted by createItems, and the ones that already exist are ignored.
+//
+// Since both need to know the result of each item, they run outside the default transaction of gorm,
+// and are not supported in transactions begun by the user.
+func upsert(db *gorm.DB, onConflict clause.OnConflict, createItems func(db *gorm.DB)) {
+	if !onConflict.UpdateAll && !onConflict.DoNothing {
+		db.AddError(fmt.Errorf("%w: ON CONFLICT other than UpdateAll and DoNothing", ErrDynmgrmAreNotSupported))
+		return
+	}
+	restore, ok := outsideDefaultTransaction(db, "ON CONFLICT")
+	if !ok {
+		return
+	}
+	defer restore()
+	if onConflict.UpdateAll {
+		putItems(db, nil)
+		return
+	}
+	createItems(db)
+	ignoreDuplicateItems(db)
+}
+
+// outsideDefaultTransaction replaces the default transaction of gorm in the statement with the connection pool,
+// and returns the function that restores it.
+// In transactions begun by the user, it adds the error that the feature is not supported, and returns false.
+func outsideDefaultTransaction(db *gorm.DB, feature string) (restore func(), ok bool) {
+	connPool := db.Statement.ConnPool
+	if _, ok := connPool.(gorm.TxCommitter); !ok {
+		return func() {}, true
+	}
+	if inTransaction(db) {
+		db.AddError(fmt.Errorf("%w: %s in transactions", ErrDynmgrmAreNotSupported, feature))
+		return nil, false
+	}
+	db.Statement.ConnPool = db.Config.ConnPool
+	return func() {
+		db.Statement.ConnPool = connPool
+	}, true
+}
+
+// putItems writes the items by PutItem.
+// With the condition, each item is written only if the existing one satisfies it.
+func putItems(db *gorm.DB, condition *putCondition) {
+	dialector, ok := db.Dialector.(*Dialector)
+	if !ok || dialector.client == nil || db.Statement.Schema == nil {
+		db.AddError(ErrDynmgrmAreNotSupported)
+		return
+	}
+	values := callbacks.ConvertToCreateValues(db.Statement)
+	for _, row := range values.Values {
+		item := make(map[string]types.AttributeValue, len(values.Columns))
+		for i, column := range values.Columns {
+			omitted, err := isOmittedOnInsert(db.Statement, column.Name, row[i])
+			if err != nil {
+				db.AddError(err)
+				return
+			}
+			if omitted {
+				continue
+			}
+			av, err := godynamo.ToAttributeValue(row[i])
+			if err != nil {
+				db.AddError(fmt.Errorf("error marshalling attribute %s: %w", column.Name, err))
+				return
+			}
+			item[column.Name] = av
+		}
+		if db.DryRun {
+			continue
+		}
+		input := &dynamodb.PutItemInput{
+			TableName: aws.String(db.Statement.Table),
+			Item:      item,
+		}
+		if condition != nil {
+			input.ConditionExpression = aws.String(condition.expression)
+			input.ReturnValuesOnConditionCheckFailure = types.ReturnValuesOnConditionCheckFailureAllOld
+			if len(condition.names) > 0 {
+				input.ExpressionAttributeNames = condition.names
+			}
+			if len(condition.values) > 0 {
+				input.ExpressionAttributeValues = condition.values
+			}
+		}
+		_, err := dialector.client.PutItem(db.Statement.Context, input)
+		if err != nil {
+			db.AddError(conditionFailedError(err, false))
+			return
+		}
+		db.RowsAffected++
+	}
+}
+
+// ignoreDuplicateItems removes the errors of the items that already exist.
+func ignoreDuplicateItems(db *gorm.DB) {
+	if db.Error == nil {
+		return
+	}
+	if isDuplicateItem(db.Error) {
+		db.Error = nil
+		return
+	}
+	var bce *BatchCreateError
+	if !errors.As(db.Error, &bce) {
+		return
+	}
+	failures := slices.DeleteFunc(slices.Clone(bce.Failures), func(f BatchCreateFailure) bool {
+		return f.Code == string(types.BatchStatementErrorCodeEnumDuplicateItem)
+	})
+	if len(failures) == 0 {
+		db.Error = nil
+		return
+	}
+	db.Error = &BatchCreateError{Failures: failures}
+}
+
+// isDuplicateItem reports whether the error is caused by the item that already exists.
+func isDuplicateItem(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "DuplicateItemException"
+}
@@ -0,0 +1,193 @@
+package dynmgrm
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/miyamo2/dynmgrm/internal/mocks"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"testing"
+)
+
+func TestUpsert(t *testing.T) {
+	type want struct {
+		puts         []*dynamodb.PutItemInput
+		execs        int
+		rowsAffected int64
+		err          error
+	}
+	type test struct {
+		operation       func(db *gorm.DB) *gorm.DB
+		config          *gorm.Config
+		poolErr         error
+		setupMockClient func(client *mocks.MockDynamoDBAPI, puts *[]*dynamodb.PutItemInput)
+		want            want
+	}
+	recordPuts := func(times int) func(client *mocks.MockDynamoDBAPI, puts *[]*dynamodb.PutItemInput) {
+		return func(client *mocks.MockDynamoDBAPI, puts *[]*dynamodb.PutItemInput) {
+			client.EXPECT().PutItem(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+					*puts = append(*puts, in)
+					return &dynamodb.PutItemOutput{}, nil
+				}).Times(times)
+		}
+	}
+	put := func(sk int, name string) *dynamodb.PutItemInput {
+		item := map[string]types.AttributeValue{
+			"pk":   &types.AttributeValueMemberS{Value: "Partition1"},
+			"sk":   &types.AttributeValueMemberN{Value: []string{"0", "1"}[sk]},
+			"name": &types.AttributeValueMemberS{Value: name},
+		}
+		return &dynamodb.PutItemInput{TableName: aws.String("test_items"), Item: item}
+	}
+	updateAll := clause.OnConflict{UpdateAll: true}
+	doNothing := clause.OnConflict{DoNothing: true}
+	errDuplicateItem := &types.DuplicateItemException{Message: aws.String("Duplicate primary key exists in table")}
+	tests := map[string]test{
+		"happy_path/update_all": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(updateAll).Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+			},
+			setupMockClient: recordPuts(1),
+			want: want{
+				puts:         []*dynamodb.PutItemInput{put(1, "Item1")},
+				rowsAffected: 1,
+			},
+		},
+		"happy_path/update_all_with_slice": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(updateAll).Create(&[]testItem{
+					{PK: "Partition1", SK: 0, Name: "Item0"},
+					{PK: "Partition1", SK: 1},
+				})
+			},
+			setupMockClient: recordPuts(2),
+			want: want{
+				puts:         []*dynamodb.PutItemInput{put(0, "Item0"), put(1, "")},
+				rowsAffected: 2,
+			},
+		},
+		"happy_path/update_all_with_default_transaction": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(updateAll).Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+			},
+			config:          &gorm.Config{},
+			setupMockClient: recordPuts(1),
+			want: want{
+				puts:         []*dynamodb.PutItemInput{put(1, "Item1")},
+				rowsAffected: 1,
+			},
+		},
+		"happy_path/save_with_default_transaction": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Save(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+			},
+			config: &gorm.Config{},
+			setupMockClient: func(client *mocks.MockDynamoDBAPI, _ *[]*dynamodb.PutItemInput) {
+				client.EXPECT().ExecuteTransaction(gomock.Any(), gomock.Any()).
+					Return(&dynamodb.ExecuteTransactionOutput{}, nil).Times(1)
+			},
+			want: want{
+				rowsAffected: 1,
+			},
+		},
+		"happy_path/save_versioned_item_with_default_transaction": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Save(&versionedItem{PK: "Partition1", SK: 1, Name: "Item1", Version: 3})
+			},
+			config: &gorm.Config{},
+			setupMockClient: func(client *mocks.MockDynamoDBAPI, _ *[]*dynamodb.PutItemInput) {
+				client.EXPECT().ExecuteTransaction(gomock.Any(), gomock.Any()).
+					Return(&dynamodb.ExecuteTransactionOutput{}, nil).Times(1)
+			},
+			want: want{
+				rowsAffected: 1,
+			},
+		},
+		"happy_path/do_nothing": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(doNothing).Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+			},
+			poolErr: errDuplicateItem,
+			want: want{
+				execs: 1,
+			},
+		},
+		"happy_path/do_nothing_with_slice": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(doNothing).Create(&[]testItem{
+					{PK: "Partition1", SK: 0, Name: "Item0"},
+					{PK: "Partition1", SK: 1, Name: "Item1"},
+				})
+			},
+			setupMockClient: func(client *mocks.MockDynamoDBAPI, _ *[]*dynamodb.PutItemInput) {
+				client.EXPECT().BatchExecuteStatement(gomock.Any(), gomock.Any()).
+					Return(&dynamodb.BatchExecuteStatementOutput{
+						Responses: []types.BatchStatementResponse{
+							{},
+							{Error: &types.BatchStatementError{Code: types.BatchStatementErrorCodeEnumDuplicateItem}},
+						},
+					}, nil).Times(1)
+			},
+			want: want{
+				rowsAffected: 1,
+			},
+		},
+		"unhappy_path/in_transaction": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Begin().Clauses(updateAll).Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+			},
+			want: want{
+				err: ErrDynmgrmAreNotSupported,
+			},
+		},
+		"unhappy_path/do_updates": {
+			operation: func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"name"})}).
+					Create(&testItem{PK: "Partition1", SK: 1, Name: "Item1"})
+			},
+			want: want{
+				err: ErrDynmgrmAreNotSupported,
+			},
+		},
+	}
+	opts := []cmp.Option{
+		cmpopts.IgnoreUnexported(
+			dynamodb.PutItemInput{},
+			types.AttributeValueMemberS{},
+			types.AttributeValueMemberN{},
+		),
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockDynamoDBAPI(ctrl)
+			var puts []*dynamodb.PutItemInput
+			if setup := tt.setupMockClient; setup != nil {
+				setup(client, &puts)
+			}
+			pool := &fakeConnPool{rowsAffected: 1, err: tt.poolErr}
+			db := openTestDB(t, pool, client, tt.config)
+
+			result := tt.operation(db)
+			if !errors.Is(result.Error, tt.want.err) {
+				t.Fatalf("Create() error = %v, want %v", result.Error, tt.want.err)
+			}
+			if diff := cmp.Diff(tt.want.puts, puts, opts...); diff != "" {
+				t.Errorf("PutItemInput mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.execs, len(pool.execs)); diff != "" {
+				t.Errorf("executions mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.rowsAffected, result.RowsAffected); diff != "" {
+				t.Errorf("RowsAffected mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}